@@ -1,7 +1,9 @@
 package collector
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,7 +35,7 @@ var (
 // Collector is the interface a collector has to implement.
 type Collector interface {
 	// Get metrics and expose them via prometheus registry.
-	Update(ch chan<- prometheus.Metric) error
+	Update(ctx context.Context, ch chan<- prometheus.Metric) error
 
 	// Describe metrics
 	UpdateDescribe(ch chan<- *prometheus.Desc)
@@ -42,22 +44,24 @@ type Collector interface {
 // IbmSpectrumCollector implements the prometheus.Collector interface.
 type IbmSpectrumCollector struct {
 	Collectors        map[string]Collector
-	ibmSpectrumClient spectrumservice.Client
+	ibmSpectrumClient spectrumservice.API
 	logger            *zap.SugaredLogger
 	regex             string
 	metrics           map[int]*prometheus.Desc
 	properties        map[string]*prometheus.Desc
+	// performanceMetrics are the configured performance metrics, by IBM Spectrum metric ID
+	performanceMetrics map[int]monitoring.Metric
 }
 
 var (
 	factories = make(map[string]func(config monitoring.MetricsConfig, logger *zap.Logger,
-		spectrumClient spectrumservice.Client) (Collector, error))
+		spectrumClient spectrumservice.API, filter string) (Collector, error))
 	State  = make(map[string]*bool)
 	Filter = make(map[string]*string)
 )
 
 func registerCollector(collector string, isDefaultEnabled bool, factory func(config monitoring.MetricsConfig, logger *zap.Logger,
-	spectrumClient spectrumservice.Client) (Collector, error)) {
+	spectrumClient spectrumservice.API, filter string) (Collector, error)) {
 	var helpDefaultState string
 	if isDefaultEnabled {
 		helpDefaultState = "enabled"
@@ -80,14 +84,35 @@ func registerCollector(collector string, isDefaultEnabled bool, factory func(con
 	factories[collector] = factory
 }
 
+// ServerSettings returns the collectors state and filters of a server, the flags giving the defaults
+func ServerSettings(server monitoring.ServerConfig) (map[string]*bool, map[string]*string) {
+	state := make(map[string]*bool)
+	filter := make(map[string]*string)
+
+	for key := range factories {
+		enabled, regex := *State[key], *Filter[key]
+		if override, found := server.Collectors[key]; found {
+			if override.Enabled != nil {
+				enabled = *override.Enabled
+			}
+			if override.Filter != nil {
+				regex = *override.Filter
+			}
+		}
+		state[key] = &enabled
+		filter[key] = &regex
+	}
+	return state, filter
+}
+
 // NewIbmSpectrumCollector create new collector instance
 func NewIbmSpectrumCollector(config monitoring.MetricsConfig, logger *zap.Logger,
-	spectrumClient spectrumservice.Client) (*IbmSpectrumCollector, error) {
+	spectrumClient spectrumservice.API, state map[string]*bool, filter map[string]*string) (*IbmSpectrumCollector, error) {
 
 	collectors := make(map[string]Collector)
-	for key, enabled := range State {
+	for key, enabled := range state {
 		if *enabled {
-			collector, err := factories[key](config, logger, spectrumClient)
+			collector, err := factories[key](config, logger, spectrumClient, *filter[key])
 			if err != nil {
 				return nil, err
 			}
@@ -95,7 +120,26 @@ func NewIbmSpectrumCollector(config monitoring.MetricsConfig, logger *zap.Logger
 		}
 	}
 
-	return &IbmSpectrumCollector{Collectors: collectors, logger: logger.Sugar()}, nil
+	performanceMetrics := make(map[int]monitoring.Metric)
+	for _, metric := range config.PerformanceMetrics() {
+		performanceMetrics[metric.MetricID] = metric
+	}
+
+	return &IbmSpectrumCollector{Collectors: collectors, ibmSpectrumClient: spectrumClient, logger: logger.Sugar(),
+		performanceMetrics: performanceMetrics}, nil
+}
+
+// collectionFailed logs the failure of a collector according to its kind and reports it in the scrape metrics
+func collectionFailed(ch chan<- prometheus.Metric, logger *zap.SugaredLogger, collector string, err error) {
+	switch {
+	case spectrumservice.IsUnauthorized(err):
+		logger.Errorf("Error during authentication of the %s collector. %v", collector, err)
+	case spectrumservice.IsRetryable(err):
+		logger.Warnf("Transient error in the %s collector. %v", collector, err)
+	default:
+		logger.Errorf("Error in the %s collector. %v", collector, err)
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 0, collector)
 }
 
 // Describe all metrics
@@ -114,6 +158,7 @@ func (c *IbmSpectrumCollector) Describe(ch chan<- *prometheus.Desc) {
 
 	ch <- scrapeDurationDesc
 	ch <- scrapeSuccessDesc
+	ch <- metricInfoDesc
 
 	for _, metric := range c.metrics {
 		ch <- metric
@@ -124,15 +169,24 @@ func (c *IbmSpectrumCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 }
 
-// CollectFromStorage the metrics from IBM Spectrum
+// Collect the metrics from IBM Spectrum
 func (c *IbmSpectrumCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(context.Background(), ch)
+}
+
+// WithContext returns a prometheus collector bound to the given context, e.g. the scrape deadline
+func (c *IbmSpectrumCollector) WithContext(ctx context.Context) prometheus.Collector {
+	return &scrapeCollector{IbmSpectrumCollector: c, ctx: ctx}
+}
+
+func (c *IbmSpectrumCollector) collect(ctx context.Context, ch chan<- prometheus.Metric) {
 	c.logger.Info("Starting IBM Spectrum collect.")
 
 	wg := sync.WaitGroup{}
 	wg.Add(len(c.Collectors))
 	for name, ca := range c.Collectors {
 		go func(name string, ca Collector) {
-			err := ca.Update(ch)
+			err := ca.Update(ctx, ch)
 			if err != nil {
 				c.logger.Error("Error collecting metrics.", err)
 			}
@@ -140,4 +194,23 @@ func (c *IbmSpectrumCollector) Collect(ch chan<- prometheus.Metric) {
 		}(name, ca)
 	}
 	wg.Wait()
+
+	// the names and units of the metrics are known once IBM Spectrum returned them
+	for metricID, metric := range c.performanceMetrics {
+		if detail, found := c.ibmSpectrumClient.MetricDetail(metricID); found {
+			ch <- prometheus.MustNewConstMetric(metricInfoDesc, prometheus.GaugeValue, 1, metric.PrometheusName,
+				strconv.Itoa(metricID), detail.Name, detail.Units)
+		}
+	}
+}
+
+// scrapeCollector collects the metrics within the context of a scrape
+type scrapeCollector struct {
+	*IbmSpectrumCollector
+	ctx context.Context
+}
+
+// Collect the metrics from IBM Spectrum, stopping when the scrape context is done
+func (c *scrapeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(c.ctx, ch)
 }
@@ -0,0 +1,993 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// fakeAPI returns canned collections, the calls not implemented panic
+type fakeAPI struct {
+	spectrumservice.API
+	storage     *spectrumservice.CollectedStorageMetrics
+	volumes     *spectrumservice.CollectedVolumeMetrics
+	pools       *spectrumservice.CollectedPoolMetrics
+	hosts       *spectrumservice.CollectedHostMetrics
+	ports       *spectrumservice.CollectedPortMetrics
+	nodes       *spectrumservice.CollectedNodeMetrics
+	mdisks      *spectrumservice.CollectedManagedDiskMetrics
+	disks       *spectrumservice.CollectedDiskMetrics
+	switches    *spectrumservice.CollectedSwitchMetrics
+	switchPorts *spectrumservice.CollectedSwitchPortMetrics
+	fabrics     *spectrumservice.CollectedFabricMetrics
+	catalog     map[int]spectrumservice.MetricDetail
+}
+
+func (f *fakeAPI) CollectFromStorage(ctx context.Context, filter string) (*spectrumservice.CollectedStorageMetrics, error) {
+	return f.storage, nil
+}
+
+func (f *fakeAPI) CollectFromVolumes(ctx context.Context, filter string) (*spectrumservice.CollectedVolumeMetrics, error) {
+	return f.volumes, nil
+}
+
+func (f *fakeAPI) CollectFromPools(ctx context.Context, filter string) (*spectrumservice.CollectedPoolMetrics, error) {
+	return f.pools, nil
+}
+
+func (f *fakeAPI) CollectFromHosts(ctx context.Context, filter string) (*spectrumservice.CollectedHostMetrics, error) {
+	return f.hosts, nil
+}
+
+func (f *fakeAPI) CollectFromPorts(ctx context.Context, filter string) (*spectrumservice.CollectedPortMetrics, error) {
+	return f.ports, nil
+}
+
+func (f *fakeAPI) CollectFromNodes(ctx context.Context, filter string) (*spectrumservice.CollectedNodeMetrics, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeAPI) CollectFromManagedDisks(ctx context.Context,
+	filter string) (*spectrumservice.CollectedManagedDiskMetrics, error) {
+	return f.mdisks, nil
+}
+
+func (f *fakeAPI) CollectFromDisks(ctx context.Context, filter string) (*spectrumservice.CollectedDiskMetrics, error) {
+	return f.disks, nil
+}
+
+func (f *fakeAPI) CollectFromSwitch(ctx context.Context, filter string) (*spectrumservice.CollectedSwitchMetrics, error) {
+	return f.switches, nil
+}
+
+func (f *fakeAPI) CollectFromSwitchPorts(ctx context.Context,
+	filter string) (*spectrumservice.CollectedSwitchPortMetrics, error) {
+	return f.switchPorts, nil
+}
+
+func (f *fakeAPI) CollectFromFabrics(ctx context.Context, filter string) (*spectrumservice.CollectedFabricMetrics, error) {
+	return f.fabrics, nil
+}
+
+func (f *fakeAPI) MetricDetail(metricID int) (spectrumservice.MetricDetail, bool) {
+	detail, found := f.catalog[metricID]
+	return detail, found
+}
+
+// metricValue returns a performance metric with a single value
+func metricValue(metricID int, deviceName string, value float64) spectrumservice.MetricValue {
+	metric := spectrumservice.MetricValue{MetricID: metricID, DeviceName: deviceName}
+	metric.Current = append(metric.Current, struct {
+		X int64    `json:"x"`
+		Y *float64 `json:"y"`
+	}{X: 1589834596000, Y: &value})
+	return metric
+}
+
+func TestPropertyDecimalComma(t *testing.T) {
+	config := parseConfig(t, `
+decimal_separator: ","
+metrics:
+  pools:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_usable_capacity_bytes
+        prometheus_help: Usable Capacity
+        unit: GiB
+`)
+
+	api := &fakeAPI{pools: &spectrumservice.CollectedPoolMetrics{Metrics: []*spectrumservice.PoolsMetrics{
+		{Pool: spectrumservice.Pool{Name: "pool1", StorageSystem: "v1234", Capacity: "1.024,00"}},
+		{Pool: spectrumservice.Pool{Name: "pool2", StorageSystem: "v1234", Capacity: "0,125 TiB"}},
+	}}}
+
+	expected := `
+# HELP storage_usable_capacity_bytes Usable Capacity
+# TYPE storage_usable_capacity_bytes gauge
+storage_usable_capacity_bytes{pool_name="pool1",storage_system="v1234"} 1.099511627776e+12
+storage_usable_capacity_bytes{pool_name="pool2",storage_system="v1234"} 1.37438953472e+11
+`
+	c := newTestCollector(t, config, api, "pool")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_usable_capacity_bytes"); err != nil {
+		t.Error(err)
+	}
+}
+
+// parseConfig parses a metrics configuration
+func parseConfig(t *testing.T, config string) monitoring.MetricsConfig {
+	var metricsConfig monitoring.MetricsConfig
+	if err := yaml.UnmarshalStrict([]byte(config), &metricsConfig); err != nil {
+		t.Fatalf("invalid metrics configuration: %v", err)
+	}
+	return metricsConfig
+}
+
+// newTestCollector returns a collector with only the given collectors enabled
+func newTestCollector(t *testing.T, config monitoring.MetricsConfig, api spectrumservice.API,
+	enabled ...string) *IbmSpectrumCollector {
+	state := make(map[string]*bool)
+	filter := make(map[string]*string)
+	for key := range factories {
+		on := false
+		regex := ".*"
+		for _, name := range enabled {
+			on = on || name == key
+		}
+		state[key] = &on
+		filter[key] = &regex
+	}
+
+	c, err := NewIbmSpectrumCollector(config, zap.NewNop(), api, state, filter)
+	if err != nil {
+		t.Fatalf("NewIbmSpectrumCollector() returned an error: %v", err)
+	}
+	return c
+}
+
+func TestPoolCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  pools:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_usable_capacity_GiB
+        prometheus_help: Usable Capacity
+`)
+
+	api := &fakeAPI{pools: &spectrumservice.CollectedPoolMetrics{Metrics: []*spectrumservice.PoolsMetrics{
+		{Pool: spectrumservice.Pool{Name: "pool1", StorageSystem: "v1234", Capacity: "2,299.48"}},
+	}}}
+
+	expected := `
+# HELP storage_usable_capacity_GiB Usable Capacity
+# TYPE storage_usable_capacity_GiB gauge
+storage_usable_capacity_GiB{pool_name="pool1",storage_system="v1234"} 2299.48
+`
+	c := newTestCollector(t, config, api, "pool")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_usable_capacity_GiB"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyGroups(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  pools:
+    properties:
+      - property_name: Tier 0 Flash Capacity
+        prometheus_name: storage_pool_flash_capacity_bytes
+        prometheus_help: Flash Capacity
+        unit: GiB
+    property_groups:
+      - prometheus_name: storage_pool_tier_capacity_bytes
+        prometheus_help: Capacity of the pool by tier
+        label: tier
+        unit: GiB
+        properties:
+          - property_name: Tier 0 Flash Capacity
+            label_value: tier0_flash
+          - property_name: Enterprise HDD Capacity
+            label_value: enterprise_hdd
+          - property_name: Nearline HDD Capacity
+            label_value: nearline_hdd
+`)
+
+	api := &fakeAPI{pools: &spectrumservice.CollectedPoolMetrics{Metrics: []*spectrumservice.PoolsMetrics{
+		{Pool: spectrumservice.Pool{Name: "pool1", StorageSystem: "v1234", Tier0FlashCapacity: "2",
+			EnterpriseHDDCapacity: "1 TiB", NearlineHDDCapacity: "-"}},
+	}}}
+
+	expected := `
+# HELP storage_pool_flash_capacity_bytes Flash Capacity
+# TYPE storage_pool_flash_capacity_bytes gauge
+storage_pool_flash_capacity_bytes{pool_name="pool1",storage_system="v1234"} 2.147483648e+09
+# HELP storage_pool_tier_capacity_bytes Capacity of the pool by tier
+# TYPE storage_pool_tier_capacity_bytes gauge
+storage_pool_tier_capacity_bytes{pool_name="pool1",storage_system="v1234",tier="enterprise_hdd"} 1.099511627776e+12
+storage_pool_tier_capacity_bytes{pool_name="pool1",storage_system="v1234",tier="tier0_flash"} 2.147483648e+09
+`
+	c := newTestCollector(t, config, api, "pool")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_pool_flash_capacity_bytes",
+		"storage_pool_tier_capacity_bytes"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyGroupLabelCollision(t *testing.T) {
+	for collector, section := range map[string]string{"pool": "pools", "volume": "volumes"} {
+		config := parseConfig(t, `
+metrics:
+  `+section+`:
+    property_groups:
+      - prometheus_name: storage_tier_capacity_bytes
+        label: storage_system
+        properties:
+          - property_name: Tier 0 Flash Capacity
+            label_value: tier0_flash
+`)
+
+		state := map[string]*bool{collector: new(bool)}
+		*state[collector] = true
+		filter := map[string]*string{collector: new(string)}
+		if _, err := NewIbmSpectrumCollector(config, zap.NewNop(), &fakeAPI{}, state, filter); err == nil {
+			t.Errorf("NewIbmSpectrumCollector() expected an error for the storage_system label of the %s", section)
+		}
+	}
+}
+
+func TestPoolInfo(t *testing.T) {
+	api := &fakeAPI{pools: &spectrumservice.CollectedPoolMetrics{Metrics: []*spectrumservice.PoolsMetrics{
+		{Pool: spectrumservice.Pool{Name: "pool1", StorageSystem: "v1234", Status: "Warning", Tier: "Tier 0 Flash",
+			EasyTier: "Balanced", Encryption: "No", RAIDLevel: "RAID 6", ExtentSize: "1,024 MiB",
+			BackEndStorageSystemType: "SVC", ParentName: "parent1"}},
+	}}}
+
+	expected := `
+# HELP storage_pool_info Pool Info.
+# TYPE storage_pool_info gauge
+storage_pool_info{backend_storage_system_type="SVC",easy_tier="Balanced",encryption="No",extent_size="1,024 MiB",parent_name="parent1",pool_name="pool1",raid_level="RAID 6",storage_system="v1234",tier="Tier 0 Flash"} 1
+# HELP storage_pool_status Status of the pool.
+# TYPE storage_pool_status gauge
+storage_pool_status{pool_name="pool1",status="error",storage_system="v1234"} 0
+storage_pool_status{pool_name="pool1",status="normal",storage_system="v1234"} 0
+storage_pool_status{pool_name="pool1",status="unknown",storage_system="v1234"} 0
+storage_pool_status{pool_name="pool1",status="warning",storage_system="v1234"} 1
+`
+	c := newTestCollector(t, monitoring.MetricsConfig{}, api, "pool")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_pool_info",
+		"storage_pool_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCatalogHelp(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  storage_systems:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_avg_read_io_ops_per_second
+`)
+
+	api := &fakeAPI{
+		storage: &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+			Storage:              spectrumservice.StorageSystem{Name: "SVC1"},
+			StorageSystemMetrics: []spectrumservice.MetricValue{metricValue(803, "SVC1", 3691.71)},
+		}}},
+		catalog: map[int]spectrumservice.MetricDetail{
+			803: {Name: "Read I/O Rate - overall", Description: "Average number of read operations per second.",
+				Units: "ops/s"},
+		},
+	}
+
+	expected := `
+# HELP storage_avg_read_io_ops_per_second IBM Spectrum metric 803, described by storage_metric_info.
+# TYPE storage_avg_read_io_ops_per_second gauge
+storage_avg_read_io_ops_per_second{name="SVC1",storage_name="SVC1",type="storageSystem"} 3691.71 1589834596000
+# HELP storage_metric_info IBM Spectrum description of the exported performance metrics.
+# TYPE storage_metric_info gauge
+storage_metric_info{ibm_spectrum_metric_id="803",ibm_spectrum_name="Read I/O Rate - overall",metric="storage_avg_read_io_ops_per_second",unit="ops/s"} 1
+`
+	c := newTestCollector(t, config, api, "storage")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_avg_read_io_ops_per_second", "storage_metric_info"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServersHelp(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  storage_systems:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_avg_read_io_ops_per_second
+`)
+
+	// the servers describe the same metric differently, the help of the metric must not depend on them
+	registry := prometheus.NewRegistry()
+	for server, description := range map[string]string{"dc1": "Read operations per second.",
+		"dc2": "Average number of read operations per second."} {
+		api := &fakeAPI{
+			storage: &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+				Storage:              spectrumservice.StorageSystem{Name: "SVC-" + server},
+				StorageSystemMetrics: []spectrumservice.MetricValue{metricValue(803, "SVC-"+server, 1)},
+			}}},
+			catalog: map[int]spectrumservice.MetricDetail{803: {Name: "Read I/O Rate - overall",
+				Description: description, Units: "ops/s"}},
+		}
+		err := prometheus.WrapRegistererWith(prometheus.Labels{"spectrum_server": server}, registry).
+			Register(newTestCollector(t, config, api, "storage"))
+		if err != nil {
+			t.Fatalf("Register() returned an error: %v", err)
+		}
+	}
+
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("Gather() returned an error: %v", err)
+	}
+}
+
+func TestVolumeCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  storage_systems_and_volumes:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_avg_read_io_ops_per_second
+      prometheus_help: Average number of read operations per second.
+  volumes:
+    metrics:
+      - ibm_spectrum_metric_id: 803
+        prometheus_name: storage_volume_read_io_ops_per_second
+        prometheus_help: Average number of read operations per second.
+`)
+
+	// two volumes with the same name on different storage systems
+	storage1 := spectrumservice.StorageSystem{ID: "1", Name: "SVC1"}
+	storage2 := spectrumservice.StorageSystem{ID: "2", Name: "SVC2"}
+	api := &fakeAPI{
+		storage: &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+			Storage:              storage1,
+			StorageSystemMetrics: []spectrumservice.MetricValue{metricValue(803, "SVC1", 3691.71)},
+		}}},
+		volumes: &spectrumservice.CollectedVolumeMetrics{Metrics: []*spectrumservice.VolumeMetrics{
+			{Storage: storage1, Volume: spectrumservice.Volume{ID: "11", Name: "data ", Pool: "pool1",
+				VolumeUniqueID: "600507680C80"},
+				VolumeMetrics: []spectrumservice.MetricValue{metricValue(803, "data", 14.7)}},
+			{Storage: storage2, Volume: spectrumservice.Volume{ID: "21", Name: "data", Pool: "pool2",
+				VolumeUniqueID: "600507680C81"},
+				VolumeMetrics: []spectrumservice.MetricValue{metricValue(803, "data", 2)}},
+		}},
+	}
+
+	// the storage system series keep their labels, the volume series have their own names
+	expected := `
+# HELP storage_avg_read_io_ops_per_second Average number of read operations per second.
+# TYPE storage_avg_read_io_ops_per_second gauge
+storage_avg_read_io_ops_per_second{name="SVC1",storage_name="SVC1",type="storageSystem"} 3691.71 1589834596000
+# HELP storage_volume_read_io_ops_per_second Average number of read operations per second.
+# TYPE storage_volume_read_io_ops_per_second gauge
+storage_volume_read_io_ops_per_second{name="data",pool="pool1",storage_system="SVC1",volume_id="11",volume_uid="600507680C80"} 14.7 1589834596000
+storage_volume_read_io_ops_per_second{name="data",pool="pool2",storage_system="SVC2",volume_id="21",volume_uid="600507680C81"} 2 1589834596000
+`
+	c := newTestCollector(t, config, api, "storage", "volume")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_avg_read_io_ops_per_second", "storage_volume_read_io_ops_per_second"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVolumeProperties(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  volumes:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_volume_capacity_GiB
+        prometheus_help: Volume capacity
+      - property_name: Thin Provisioned
+        prometheus_name: storage_volume_thin_provisioned
+        prometheus_help: Whether the volume is thin provisioned
+`)
+
+	api := &fakeAPI{volumes: &spectrumservice.CollectedVolumeMetrics{Metrics: []*spectrumservice.VolumeMetrics{
+		{Storage: spectrumservice.StorageSystem{Name: "SVC1"}, Volume: spectrumservice.Volume{ID: "11", Name: "data",
+			Pool: "pool1", VolumeUniqueID: "600507680C80", Capacity: "1,024.00", ThinProvisioned: "Yes"}},
+	}}}
+
+	expected := `
+# HELP storage_volume_capacity_GiB Volume capacity
+# TYPE storage_volume_capacity_GiB gauge
+storage_volume_capacity_GiB{name="data",pool="pool1",storage_system="SVC1",volume_id="11",volume_uid="600507680C80"} 1024
+# HELP storage_volume_thin_provisioned Whether the volume is thin provisioned
+# TYPE storage_volume_thin_provisioned gauge
+storage_volume_thin_provisioned{name="data",pool="pool1",storage_system="SVC1",volume_id="11",volume_uid="600507680C80"} 1
+`
+	c := newTestCollector(t, config, api, "volume")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_volume_capacity_GiB", "storage_volume_thin_provisioned"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageSystemProperties(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  storage_systems:
+    metrics:
+      - ibm_spectrum_metric_id: 803
+        prometheus_name: storage_avg_read_io_ops_per_second
+        prometheus_help: Average number of read operations per second.
+    properties:
+      - property_name: Pool Capacity
+        prometheus_name: storage_system_pool_capacity_GiB
+        prometheus_help: Pool Capacity
+      - property_name: Total Data Reduction Savings
+        prometheus_name: storage_system_data_reduction_savings_GiB
+        prometheus_help: Total Data Reduction Savings
+`)
+	if len(config.PerformanceMetrics()) != 1 {
+		t.Fatalf("expected 1 performance metric, got %v", config.PerformanceMetrics())
+	}
+
+	api := &fakeAPI{storage: &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+		Storage: spectrumservice.StorageSystem{Name: "SVC1", PoolCapacity: "2,299.48",
+			TotalDataReductionSavings: "120.50"},
+	}}}}
+
+	expected := `
+# HELP storage_system_pool_capacity_GiB Pool Capacity
+# TYPE storage_system_pool_capacity_GiB gauge
+storage_system_pool_capacity_GiB{storage_system="SVC1"} 2299.48
+# HELP storage_system_data_reduction_savings_GiB Total Data Reduction Savings
+# TYPE storage_system_data_reduction_savings_GiB gauge
+storage_system_data_reduction_savings_GiB{storage_system="SVC1"} 120.5
+`
+	c := newTestCollector(t, config, api, "storage")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_system_pool_capacity_GiB", "storage_system_data_reduction_savings_GiB"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageSystemDataCollection(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  storage_systems:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_avg_read_io_ops_per_second
+      prometheus_help: Average number of read operations per second.
+`)
+
+	defer func(previous func() time.Time) { now = previous }(now)
+	now = func() time.Time { return time.Unix(1589834656, 0) }
+
+	storage := spectrumservice.StorageSystem{ID: "1", Name: "SVC1", DataCollection: "Warning",
+		ProbeStatus: "Never Run"}
+	api := &fakeAPI{storage: &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+		Storage: storage, StorageSystemMetrics: []spectrumservice.MetricValue{metricValue(803, "SVC1", 12)},
+	}}}}
+
+	expected := `
+# HELP storage_system_data_collection_status Status of the data collection of the storage system by IBM Spectrum.
+# TYPE storage_system_data_collection_status gauge
+storage_system_data_collection_status{status="disabled",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="error",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="normal",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="unknown",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="warning",storage_system="SVC1"} 1
+# HELP storage_system_performance_sample_age_seconds Age of the newest performance sample of the storage system, growing when its performance monitor stops.
+# TYPE storage_system_performance_sample_age_seconds gauge
+storage_system_performance_sample_age_seconds{storage_system="SVC1"} 60
+# HELP storage_system_probe_status Status of the last probe of the storage system by IBM Spectrum.
+# TYPE storage_system_probe_status gauge
+storage_system_probe_status{status="failed",storage_system="SVC1"} 0
+storage_system_probe_status{status="never_run",storage_system="SVC1"} 1
+storage_system_probe_status{status="running",storage_system="SVC1"} 0
+storage_system_probe_status{status="successful",storage_system="SVC1"} 0
+storage_system_probe_status{status="unknown",storage_system="SVC1"} 0
+storage_system_probe_status{status="warning",storage_system="SVC1"} 0
+`
+	c := newTestCollector(t, config, api, "storage")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_system_data_collection_status",
+		"storage_system_performance_sample_age_seconds", "storage_system_probe_status"); err != nil {
+		t.Error(err)
+	}
+
+	// the age keeps growing once the performance monitor stops sending samples
+	now = func() time.Time { return time.Unix(1589835596, 0) }
+	api.storage = &spectrumservice.CollectedStorageMetrics{Metrics: []*spectrumservice.StorageMetrics{{
+		Storage: storage}}}
+
+	expected = `
+# HELP storage_system_performance_sample_age_seconds Age of the newest performance sample of the storage system, growing when its performance monitor stops.
+# TYPE storage_system_performance_sample_age_seconds gauge
+storage_system_performance_sample_age_seconds{storage_system="SVC1"} 1000
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_system_performance_sample_age_seconds"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageSystemPerformanceError(t *testing.T) {
+	var performanceCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/srm/REST/api/v1/StorageSystems":
+			fmt.Fprint(w, `[{"id":"1","Name":"SVC1","Data Collection":"Warning","Probe Status":"Successful"}]`)
+		case "/srm/REST/api/v1/StorageSystems/Performance":
+			// the performance request fails after the first scrape
+			if atomic.AddInt32(&performanceCalls, 1) > 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":1,"deviceName":"SVC1","metricId":803,"current":[{"x":1589834596000,"y":12}]}]`)
+		}
+	}))
+	defer server.Close()
+
+	config := parseConfig(t, `
+metrics:
+  storage_systems:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_avg_read_io_ops_per_second
+      prometheus_help: Average number of read operations per second.
+`)
+	client, err := spectrumservice.NewClient(zap.NewNop().Sugar(), config, nil, false, "user", "password",
+		server.URL, spectrumservice.TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+	client.Retry.MaxAttempts = 1
+
+	defer func(previous func() time.Time) { now = previous }(now)
+	now = func() time.Time { return time.Unix(1589834656, 0) }
+
+	c := newTestCollector(t, config, client, "storage")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP storage_system_performance_sample_age_seconds Age of the newest performance sample of the storage system, growing when its performance monitor stops.
+# TYPE storage_system_performance_sample_age_seconds gauge
+storage_system_performance_sample_age_seconds{storage_system="SVC1"} 60
+`), "storage_system_performance_sample_age_seconds"); err != nil {
+		t.Error(err)
+	}
+
+	// the storage system is still exported without performance metrics, its samples getting older
+	now = func() time.Time { return time.Unix(1589835596, 0) }
+	expected := `
+# HELP storage_system_data_collection_status Status of the data collection of the storage system by IBM Spectrum.
+# TYPE storage_system_data_collection_status gauge
+storage_system_data_collection_status{status="disabled",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="error",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="normal",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="unknown",storage_system="SVC1"} 0
+storage_system_data_collection_status{status="warning",storage_system="SVC1"} 1
+# HELP storage_system_performance_sample_age_seconds Age of the newest performance sample of the storage system, growing when its performance monitor stops.
+# TYPE storage_system_performance_sample_age_seconds gauge
+storage_system_performance_sample_age_seconds{storage_system="SVC1"} 1000
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_system_data_collection_status",
+		"storage_system_performance_sample_age_seconds", "storage_avg_read_io_ops_per_second"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyUnits(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  pools:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_usable_capacity_bytes
+        prometheus_help: Usable Capacity
+        unit: GiB
+      - property_name: Available Pool Space
+        prometheus_name: storage_free_capacity_bytes
+        prometheus_help: Free Capacity
+        unit: GiB
+      - property_name: Compression Savings
+        prometheus_name: storage_compression_savings_ratio
+        prometheus_help: Compression Savings
+`)
+
+	api := &fakeAPI{pools: &spectrumservice.CollectedPoolMetrics{Metrics: []*spectrumservice.PoolsMetrics{
+		{Pool: spectrumservice.Pool{Name: "pool1", StorageSystem: "v1234", Capacity: "2,048.00",
+			AvailablePoolSpace: "1.5 TiB", CompressionSavings: "35%"}},
+		{Pool: spectrumservice.Pool{Name: "pool2", StorageSystem: "v1234", Capacity: "1,024",
+			AvailablePoolSpace: "N/A", CompressionSavings: "-"}},
+	}}}
+
+	expected := `
+# HELP storage_usable_capacity_bytes Usable Capacity
+# TYPE storage_usable_capacity_bytes gauge
+storage_usable_capacity_bytes{pool_name="pool1",storage_system="v1234"} 2.199023255552e+12
+storage_usable_capacity_bytes{pool_name="pool2",storage_system="v1234"} 1.099511627776e+12
+# HELP storage_free_capacity_bytes Free Capacity
+# TYPE storage_free_capacity_bytes gauge
+storage_free_capacity_bytes{pool_name="pool1",storage_system="v1234"} 1.649267441664e+12
+# HELP storage_compression_savings_ratio Compression Savings
+# TYPE storage_compression_savings_ratio gauge
+storage_compression_savings_ratio{pool_name="pool1",storage_system="v1234"} 0.35
+`
+	c := newTestCollector(t, config, api, "pool")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_usable_capacity_bytes",
+		"storage_free_capacity_bytes", "storage_compression_savings_ratio"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHostCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  hosts:
+    - ibm_spectrum_metric_id: 803
+      prometheus_name: storage_host_read_io_ops_per_second
+      prometheus_help: Average number of read operations per second.
+`)
+
+	api := &fakeAPI{hosts: &spectrumservice.CollectedHostMetrics{Metrics: []*spectrumservice.HostMetrics{{
+		Host: spectrumservice.Host{ID: "31", Name: "app01 ", OSType: "Linux", OSVersion: "RHEL 7.9",
+			IPAddress: "10.0.0.1", Ports: "2", Status: "Warning"},
+		HostMetrics: []spectrumservice.MetricValue{metricValue(803, "app01", 120)},
+		Volumes: spectrumservice.Volumes{
+			{ID: "11", Name: "data", StorageSystem: "SVC1", VolumeUniqueID: "600507680C80"},
+		},
+	}}}}
+
+	expected := `
+# HELP storage_host_info Host Info.
+# TYPE storage_host_info gauge
+storage_host_info{host="app01",host_id="31",ip_address="10.0.0.1",os_type="Linux",os_version="RHEL 7.9"} 1
+# HELP storage_host_ports Number of ports of the host.
+# TYPE storage_host_ports gauge
+storage_host_ports{host="app01",host_id="31"} 2
+# HELP storage_host_status Status of the host.
+# TYPE storage_host_status gauge
+storage_host_status{host="app01",host_id="31",status="error"} 0
+storage_host_status{host="app01",host_id="31",status="normal"} 0
+storage_host_status{host="app01",host_id="31",status="unknown"} 0
+storage_host_status{host="app01",host_id="31",status="unreachable"} 0
+storage_host_status{host="app01",host_id="31",status="warning"} 1
+# HELP storage_host_read_io_ops_per_second Average number of read operations per second.
+# TYPE storage_host_read_io_ops_per_second gauge
+storage_host_read_io_ops_per_second{host="app01",host_id="31"} 120 1589834596000
+# HELP storage_host_volume_mapping_info Volumes mapped to the host, joinable with the volume series on volume_uid.
+# TYPE storage_host_volume_mapping_info gauge
+storage_host_volume_mapping_info{host="app01",host_id="31",storage_system="SVC1",volume_id="11",volume_uid="600507680C80"} 1
+`
+	c := newTestCollector(t, config, api, "host")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_host_info", "storage_host_ports",
+		"storage_host_status", "storage_host_read_io_ops_per_second", "storage_host_volume_mapping_info"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPortCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  ports:
+    - ibm_spectrum_metric_id: 1029
+      prometheus_name: storage_port_invalid_transmission_word_rate_per_second
+      prometheus_help: Invalid transmission words per second.
+`)
+
+	storage := spectrumservice.StorageSystem{ID: "1", Name: "SVC1"}
+	api := &fakeAPI{ports: &spectrumservice.CollectedPortMetrics{Metrics: []*spectrumservice.PortMetrics{
+		{Storage: storage, Port: spectrumservice.Port{ID: "41", Name: "port1", WWPN: "500507680140ABCD",
+			Node: "node1", Speed: "16 Gbps", Status: "Error"},
+			PortMetrics: []spectrumservice.MetricValue{metricValue(1029, "port1", 0.5)}},
+		{Storage: storage, Port: spectrumservice.Port{ID: "42", Name: "port2", WWPN: "500507680140ABCE",
+			Node: "node2", Speed: "16 Gbps", Status: "Normal"}},
+	}}}
+
+	expected := `
+# HELP storage_port_invalid_transmission_word_rate_per_second Invalid transmission words per second.
+# TYPE storage_port_invalid_transmission_word_rate_per_second gauge
+storage_port_invalid_transmission_word_rate_per_second{node="node1",port="port1",port_id="41",speed="16 Gbps",storage_system="SVC1",wwpn="500507680140ABCD"} 0.5 1589834596000
+# HELP storage_port_status Status of the storage system port.
+# TYPE storage_port_status gauge
+storage_port_status{node="node1",port="port1",port_id="41",speed="16 Gbps",status="error",storage_system="SVC1",wwpn="500507680140ABCD"} 1
+storage_port_status{node="node1",port="port1",port_id="41",speed="16 Gbps",status="normal",storage_system="SVC1",wwpn="500507680140ABCD"} 0
+storage_port_status{node="node1",port="port1",port_id="41",speed="16 Gbps",status="unknown",storage_system="SVC1",wwpn="500507680140ABCD"} 0
+storage_port_status{node="node1",port="port1",port_id="41",speed="16 Gbps",status="warning",storage_system="SVC1",wwpn="500507680140ABCD"} 0
+storage_port_status{node="node2",port="port2",port_id="42",speed="16 Gbps",status="error",storage_system="SVC1",wwpn="500507680140ABCE"} 0
+storage_port_status{node="node2",port="port2",port_id="42",speed="16 Gbps",status="normal",storage_system="SVC1",wwpn="500507680140ABCE"} 1
+storage_port_status{node="node2",port="port2",port_id="42",speed="16 Gbps",status="unknown",storage_system="SVC1",wwpn="500507680140ABCE"} 0
+storage_port_status{node="node2",port="port2",port_id="42",speed="16 Gbps",status="warning",storage_system="SVC1",wwpn="500507680140ABCE"} 0
+`
+	c := newTestCollector(t, config, api, "port")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"storage_port_invalid_transmission_word_rate_per_second", "storage_port_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNodeCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  nodes:
+    - ibm_spectrum_metric_id: 1000
+      prometheus_name: storage_node_cache_hit_percent
+      prometheus_help: Cache hit percentage.
+    - ibm_spectrum_metric_id: 2000
+      prometheus_name: storage_node_cpu_utilization_percent
+      prometheus_help: CPU utilization.
+  io_groups:
+    - ibm_spectrum_metric_id: 1000
+      prometheus_name: storage_node_cache_hit_percent
+      prometheus_help: Cache hit percentage.
+`)
+
+	storage := spectrumservice.StorageSystem{ID: "1", Name: "SVC1"}
+	api := &fakeAPI{nodes: &spectrumservice.CollectedNodeMetrics{
+		Metrics: []*spectrumservice.NodeMetrics{
+			{Storage: storage, Node: spectrumservice.Node{ID: "51", Name: "node1", IOGroup: "io_grp0"},
+				NodeMetrics: []spectrumservice.MetricValue{metricValue(1000, "node1", 97.5),
+					metricValue(2000, "node1", 40)}},
+		},
+		IOGroupMetrics: []*spectrumservice.IOGroupMetrics{
+			{Storage: storage, IOGroup: spectrumservice.IOGroup{ID: "61", Name: "io_grp0"},
+				IOGroupMetrics: []spectrumservice.MetricValue{metricValue(1000, "io_grp0", 95),
+					metricValue(2000, "io_grp0", 35)}},
+		},
+	}}
+
+	// the metrics of the io_groups section only are exported for the I/O groups
+	expected := `
+# HELP storage_node_cache_hit_percent Cache hit percentage.
+# TYPE storage_node_cache_hit_percent gauge
+storage_node_cache_hit_percent{io_group="io_grp0",name="io_grp0",storage_system="SVC1",type="ioGroup"} 95 1589834596000
+storage_node_cache_hit_percent{io_group="io_grp0",name="node1",storage_system="SVC1",type="node"} 97.5 1589834596000
+# HELP storage_node_cpu_utilization_percent CPU utilization.
+# TYPE storage_node_cpu_utilization_percent gauge
+storage_node_cpu_utilization_percent{io_group="io_grp0",name="node1",storage_system="SVC1",type="node"} 40 1589834596000
+`
+	c := newTestCollector(t, config, api, "node")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_node_cache_hit_percent",
+		"storage_node_cpu_utilization_percent"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestManagedDiskCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  managed_disks:
+    metrics:
+      - ibm_spectrum_metric_id: 822
+        prometheus_name: storage_managed_disk_read_response_time_milliseconds
+        prometheus_help: Average read response time.
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_managed_disk_capacity_bytes
+        prometheus_help: Managed disk capacity
+        unit: GiB
+`)
+
+	api := &fakeAPI{mdisks: &spectrumservice.CollectedManagedDiskMetrics{Metrics: []*spectrumservice.ManagedDiskMetrics{
+		{Storage: spectrumservice.StorageSystem{Name: "SVC1"}, Type: "managedDisk",
+			ManagedDisk: spectrumservice.ManagedDisk{ID: "71", Name: "mdisk0", Pool: "pool1", RAIDLevel: "RAID 6",
+				Tier: "Tier 1 Flash", Mode: "Array", Capacity: "1,024.00", Status: "Normal"},
+			ManagedDiskMetrics: []spectrumservice.MetricValue{metricValue(822, "mdisk0", 0.8)}},
+		{Storage: spectrumservice.StorageSystem{Name: "DS1"}, Type: "rank",
+			ManagedDisk: spectrumservice.ManagedDisk{ID: "72", Name: "R1", Pool: "P0", RAIDLevel: "RAID 5",
+				Capacity: "N/A", Status: "Offline"}},
+	}}}
+
+	expected := `
+# HELP storage_managed_disk_info Managed Disk Info.
+# TYPE storage_managed_disk_info gauge
+storage_managed_disk_info{mdisk_id="71",mode="Array",name="mdisk0",pool="pool1",raid_level="RAID 6",storage_system="SVC1",tier="Tier 1 Flash",type="managedDisk"} 1
+storage_managed_disk_info{mdisk_id="72",mode="",name="R1",pool="P0",raid_level="RAID 5",storage_system="DS1",tier="",type="rank"} 1
+# HELP storage_managed_disk_capacity_bytes Managed disk capacity
+# TYPE storage_managed_disk_capacity_bytes gauge
+storage_managed_disk_capacity_bytes{mdisk_id="71",name="mdisk0",pool="pool1",storage_system="SVC1",type="managedDisk"} 1.099511627776e+12
+# HELP storage_managed_disk_read_response_time_milliseconds Average read response time.
+# TYPE storage_managed_disk_read_response_time_milliseconds gauge
+storage_managed_disk_read_response_time_milliseconds{mdisk_id="71",name="mdisk0",pool="pool1",storage_system="SVC1",type="managedDisk"} 0.8 1589834596000
+# HELP storage_managed_disk_status Status of the managed disk.
+# TYPE storage_managed_disk_status gauge
+storage_managed_disk_status{mdisk_id="71",name="mdisk0",pool="pool1",status="error",storage_system="SVC1",type="managedDisk"} 0
+storage_managed_disk_status{mdisk_id="71",name="mdisk0",pool="pool1",status="normal",storage_system="SVC1",type="managedDisk"} 1
+storage_managed_disk_status{mdisk_id="71",name="mdisk0",pool="pool1",status="unknown",storage_system="SVC1",type="managedDisk"} 0
+storage_managed_disk_status{mdisk_id="71",name="mdisk0",pool="pool1",status="warning",storage_system="SVC1",type="managedDisk"} 0
+storage_managed_disk_status{mdisk_id="72",name="R1",pool="P0",status="error",storage_system="DS1",type="rank"} 0
+storage_managed_disk_status{mdisk_id="72",name="R1",pool="P0",status="normal",storage_system="DS1",type="rank"} 0
+storage_managed_disk_status{mdisk_id="72",name="R1",pool="P0",status="unknown",storage_system="DS1",type="rank"} 1
+storage_managed_disk_status{mdisk_id="72",name="R1",pool="P0",status="warning",storage_system="DS1",type="rank"} 0
+`
+	c := newTestCollector(t, config, api, "mdisk")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_managed_disk_info",
+		"storage_managed_disk_capacity_bytes", "storage_managed_disk_read_response_time_milliseconds",
+		"storage_managed_disk_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiskCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  disks:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_disk_capacity_bytes
+        prometheus_help: Disk capacity
+        unit: GiB
+`)
+
+	storage := spectrumservice.StorageSystem{Name: "SVC1"}
+	api := &fakeAPI{disks: &spectrumservice.CollectedDiskMetrics{Metrics: []*spectrumservice.DiskMetrics{
+		{Storage: storage, Disk: spectrumservice.Disk{ID: "81", Name: "drive0", Capacity: "1.75 TiB",
+			Tier: "Tier 1 Flash", Class: "SSD", Use: "Member", Enclosure: "1", Slot: "3", Status: "Normal"}},
+		{Storage: storage, Disk: spectrumservice.Disk{ID: "82", Name: "drive1", Capacity: "1.75 TiB",
+			Tier: "Tier 1 Flash", Class: "SSD", Use: "Spare", Enclosure: "1", Slot: "4", Status: "Error"}},
+	}}}
+
+	expected := `
+# HELP storage_disk_capacity_bytes Disk capacity
+# TYPE storage_disk_capacity_bytes gauge
+storage_disk_capacity_bytes{disk_id="81",name="drive0",storage_system="SVC1"} 1.924145348608e+12
+storage_disk_capacity_bytes{disk_id="82",name="drive1",storage_system="SVC1"} 1.924145348608e+12
+# HELP storage_disk_in_use Whether the disk is in use, 1 for a member of an array.
+# TYPE storage_disk_in_use gauge
+storage_disk_in_use{disk_id="81",name="drive0",storage_system="SVC1"} 1
+storage_disk_in_use{disk_id="82",name="drive1",storage_system="SVC1"} 0
+# HELP storage_disk_info Disk Info.
+# TYPE storage_disk_info gauge
+storage_disk_info{class="SSD",disk_id="81",enclosure="1",model="",name="drive0",slot="3",storage_system="SVC1",tier="Tier 1 Flash",use="member",vendor=""} 1
+storage_disk_info{class="SSD",disk_id="82",enclosure="1",model="",name="drive1",slot="4",storage_system="SVC1",tier="Tier 1 Flash",use="spare",vendor=""} 1
+# HELP storage_disk_spare Whether the disk is a spare, 1 for a spare disk.
+# TYPE storage_disk_spare gauge
+storage_disk_spare{disk_id="81",name="drive0",storage_system="SVC1"} 0
+storage_disk_spare{disk_id="82",name="drive1",storage_system="SVC1"} 1
+# HELP storage_disk_status Status of the disk.
+# TYPE storage_disk_status gauge
+storage_disk_status{disk_id="81",name="drive0",status="error",storage_system="SVC1"} 0
+storage_disk_status{disk_id="81",name="drive0",status="normal",storage_system="SVC1"} 1
+storage_disk_status{disk_id="81",name="drive0",status="unknown",storage_system="SVC1"} 0
+storage_disk_status{disk_id="81",name="drive0",status="warning",storage_system="SVC1"} 0
+storage_disk_status{disk_id="82",name="drive1",status="error",storage_system="SVC1"} 1
+storage_disk_status{disk_id="82",name="drive1",status="normal",storage_system="SVC1"} 0
+storage_disk_status{disk_id="82",name="drive1",status="unknown",storage_system="SVC1"} 0
+storage_disk_status{disk_id="82",name="drive1",status="warning",storage_system="SVC1"} 0
+`
+	c := newTestCollector(t, config, api, "disk")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_disk_capacity_bytes",
+		"storage_disk_in_use", "storage_disk_info", "storage_disk_spare", "storage_disk_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSwitchCollector(t *testing.T) {
+	api := &fakeAPI{switches: &spectrumservice.CollectedSwitchMetrics{Metrics: []*spectrumservice.SwitchMetrics{{
+		Switch: spectrumservice.Switch{ID: "5", Name: "sw1", Fabric: "fabricA", Vendor: "Brocade", Model: "G620",
+			Firmware: "v8.2.1c", IPAddress: "10.0.0.5", WWN: "100000051E0F2A3B", SerialNumber: "ABC1234",
+			PrincipalSwitchOfFabric: "Yes", Status: "Normal", ProbeStatus: "Successful",
+			PerformanceMonitorStatus: "Never Run", LastSuccessfulProbe: "1589834596000",
+			LastSuccessfulMonitor: "2020-05-18T20:43:16Z"},
+	}}}}
+
+	expected := `
+# HELP storage_switch_info Switch Info.
+# TYPE storage_switch_info gauge
+storage_switch_info{fabric="fabricA",firmware="v8.2.1c",ip_address="10.0.0.5",model="G620",name="sw1",principal_switch_of_fabric="Yes",serial_number="ABC1234",vendor="Brocade",wwn="100000051E0F2A3B"} 1
+# HELP storage_switch_last_successful_monitor_timestamp_seconds Time of the last successful performance monitoring of the switch by IBM Spectrum.
+# TYPE storage_switch_last_successful_monitor_timestamp_seconds gauge
+storage_switch_last_successful_monitor_timestamp_seconds{name="sw1"} 1.589834596e+09
+# HELP storage_switch_last_successful_probe_timestamp_seconds Time of the last successful probe of the switch by IBM Spectrum.
+# TYPE storage_switch_last_successful_probe_timestamp_seconds gauge
+storage_switch_last_successful_probe_timestamp_seconds{name="sw1"} 1.589834596e+09
+# HELP storage_switch_monitor_status Status of the performance monitor of the switch in IBM Spectrum.
+# TYPE storage_switch_monitor_status gauge
+storage_switch_monitor_status{name="sw1",status="disabled"} 0
+storage_switch_monitor_status{name="sw1",status="failed"} 0
+storage_switch_monitor_status{name="sw1",status="never_run"} 1
+storage_switch_monitor_status{name="sw1",status="running"} 0
+storage_switch_monitor_status{name="sw1",status="stopped"} 0
+storage_switch_monitor_status{name="sw1",status="unknown"} 0
+storage_switch_monitor_status{name="sw1",status="warning"} 0
+# HELP storage_switch_probe_status Status of the last probe of the switch by IBM Spectrum.
+# TYPE storage_switch_probe_status gauge
+storage_switch_probe_status{name="sw1",status="failed"} 0
+storage_switch_probe_status{name="sw1",status="never_run"} 0
+storage_switch_probe_status{name="sw1",status="running"} 0
+storage_switch_probe_status{name="sw1",status="successful"} 1
+storage_switch_probe_status{name="sw1",status="unknown"} 0
+storage_switch_probe_status{name="sw1",status="warning"} 0
+`
+	c := newTestCollector(t, monitoring.MetricsConfig{}, api, "switch")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_switch_info",
+		"storage_switch_last_successful_monitor_timestamp_seconds",
+		"storage_switch_last_successful_probe_timestamp_seconds", "storage_switch_monitor_status",
+		"storage_switch_probe_status"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSwitchPortCollector(t *testing.T) {
+	config := parseConfig(t, `
+metrics:
+  switch_ports:
+    - ibm_spectrum_metric_id: 880
+      prometheus_name: storage_switch_port_crc_errors_per_second
+      prometheus_help: CRC errors per second.
+`)
+
+	sw := spectrumservice.Switch{ID: "5", Name: "sw1", Fabric: "fabricA"}
+	api := &fakeAPI{switchPorts: &spectrumservice.CollectedSwitchPortMetrics{Metrics: []*spectrumservice.SwitchPortMetrics{
+		{Switch: sw, Port: spectrumservice.SwitchPort{ID: "91", Name: "port0", PortIndex: "0",
+			WWPN: "2000000533A1B2C3", Type: "F-Port", Speed: "16 Gbps", State: "Online"},
+			SwitchPortMetrics: []spectrumservice.MetricValue{metricValue(880, "port0", 1.5)}},
+	}}}
+
+	expected := `
+# HELP storage_switch_port_crc_errors_per_second CRC errors per second.
+# TYPE storage_switch_port_crc_errors_per_second gauge
+storage_switch_port_crc_errors_per_second{fabric="fabricA",port_index="0",switch="sw1",wwpn="2000000533A1B2C3"} 1.5 1589834596000
+# HELP storage_switch_port_info Switch Port Info.
+# TYPE storage_switch_port_info gauge
+storage_switch_port_info{fabric="fabricA",port="port0",port_index="0",speed="16 Gbps",switch="sw1",type="F-Port",wwpn="2000000533A1B2C3"} 1
+# HELP storage_switch_port_speed_bits_per_second Speed of the switch port.
+# TYPE storage_switch_port_speed_bits_per_second gauge
+storage_switch_port_speed_bits_per_second{fabric="fabricA",port_index="0",switch="sw1",wwpn="2000000533A1B2C3"} 1.6e+10
+# HELP storage_switch_port_state State of the switch port.
+# TYPE storage_switch_port_state gauge
+storage_switch_port_state{fabric="fabricA",port_index="0",state="offline",switch="sw1",wwpn="2000000533A1B2C3"} 0
+storage_switch_port_state{fabric="fabricA",port_index="0",state="online",switch="sw1",wwpn="2000000533A1B2C3"} 1
+storage_switch_port_state{fabric="fabricA",port_index="0",state="unknown",switch="sw1",wwpn="2000000533A1B2C3"} 0
+`
+	c := newTestCollector(t, config, api, "switch_port")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_switch_port_crc_errors_per_second",
+		"storage_switch_port_info", "storage_switch_port_speed_bits_per_second", "storage_switch_port_state"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFabricCollector(t *testing.T) {
+	api := &fakeAPI{fabrics: &spectrumservice.CollectedFabricMetrics{Metrics: []*spectrumservice.FabricMetrics{{
+		Fabric: spectrumservice.Fabric{Name: "fabricA", WWN: "100000051E0F2A3B", Vendor: "Brocade",
+			PrincipalSwitch: "sw1", Status: "Warning", Switches: "2", Ports: "96"},
+		Switches: []spectrumservice.Switch{{Name: "sw1"}, {Name: "sw2"}},
+	}}}}
+
+	expected := `
+# HELP storage_fabric_info Fabric Info.
+# TYPE storage_fabric_info gauge
+storage_fabric_info{fabric="fabricA",fabric_wwn="100000051E0F2A3B",principal_switch="sw1",vendor="Brocade",virtual_fabric=""} 1
+# HELP storage_fabric_ports Number of ports of the fabric.
+# TYPE storage_fabric_ports gauge
+storage_fabric_ports{fabric="fabricA"} 96
+# HELP storage_fabric_status Status of the fabric.
+# TYPE storage_fabric_status gauge
+storage_fabric_status{fabric="fabricA",status="error"} 0
+storage_fabric_status{fabric="fabricA",status="normal"} 0
+storage_fabric_status{fabric="fabricA",status="unknown"} 0
+storage_fabric_status{fabric="fabricA",status="warning"} 1
+# HELP storage_fabric_switch_info Switches member of the fabric.
+# TYPE storage_fabric_switch_info gauge
+storage_fabric_switch_info{fabric="fabricA",switch="sw1"} 1
+storage_fabric_switch_info{fabric="fabricA",switch="sw2"} 1
+# HELP storage_fabric_switches Number of switches of the fabric.
+# TYPE storage_fabric_switches gauge
+storage_fabric_switches{fabric="fabricA"} 2
+`
+	c := newTestCollector(t, monitoring.MetricsConfig{}, api, "fabric")
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "storage_fabric_info",
+		"storage_fabric_ports", "storage_fabric_status", "storage_fabric_switch_info",
+		"storage_fabric_switches"); err != nil {
+		t.Error(err)
+	}
+}
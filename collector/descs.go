@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var metricInfoDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "metric", "info"),
+	"IBM Spectrum description of the exported performance metrics.",
+	[]string{"metric", "ibm_spectrum_metric_id", "ibm_spectrum_name", "unit"},
+	nil,
+)
+
+// metricDescs are the descriptions of the performance metrics of a collector, by metric ID
+type metricDescs struct {
+	descs map[int]*prometheus.Desc
+}
+
+func newMetricDescs(labels []string, configs ...[]monitoring.Metric) *metricDescs {
+	descs := make(map[int]*prometheus.Desc)
+	for _, config := range configs {
+		for _, metric := range config {
+			descs[metric.MetricID] = prometheus.NewDesc(metric.PrometheusName, metricHelp(metric), labels, nil)
+		}
+	}
+
+	return &metricDescs{descs: descs}
+}
+
+// get returns the description of the metric, nil when the metric is not configured
+func (d *metricDescs) get(metricID int) *prometheus.Desc {
+	return d.descs[metricID]
+}
+
+// metric returns the latest available value of a performance metric, nil when the metric is not configured
+// or has no value
+func (d *metricDescs) metric(metricValue spectrumservice.MetricValue, labelValues ...string) prometheus.Metric {
+	desc := d.get(metricValue.MetricID)
+	if desc == nil {
+		return nil
+	}
+
+	for x := len(metricValue.Current) - 1; x >= 0; x-- {
+		current := metricValue.Current[x]
+		if current.Y != nil {
+			return prometheus.NewMetricWithTimestamp(time.Unix(0, current.X*int64(time.Millisecond)),
+				prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, *current.Y, labelValues...))
+		}
+	}
+	return nil
+}
+
+func (d *metricDescs) describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range d.descs {
+		ch <- desc
+	}
+}
+
+// metricHelp returns the configured help of the metric, or a help depending only on the metric ID.
+// A metric name must have the same help for all the servers, the IBM Spectrum description is given by
+// storage_metric_info instead.
+func metricHelp(metric monitoring.Metric) string {
+	if metric.PrometheusHelp != "" {
+		return metric.PrometheusHelp
+	}
+	return fmt.Sprintf("IBM Spectrum metric %d, described by storage_metric_info.", metric.MetricID)
+}
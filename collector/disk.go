@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	// diskLabels label the physical drive metrics
+	diskLabels = []string{"name", "disk_id", "storage_system"}
+
+	diskInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "disk", "info"),
+		"Disk Info.",
+		append(append([]string{}, diskLabels...), "tier", "class", "use", "enclosure", "slot", "vendor", "model"),
+		nil,
+	)
+
+	diskSpare = prometheus.NewDesc(prometheus.BuildFQName(namespace, "disk", "spare"),
+		"Whether the disk is a spare, 1 for a spare disk.",
+		diskLabels,
+		nil,
+	)
+
+	diskInUse = prometheus.NewDesc(prometheus.BuildFQName(namespace, "disk", "in_use"),
+		"Whether the disk is in use, 1 for a member of an array.",
+		diskLabels,
+		nil,
+	)
+)
+
+func init() {
+	registerCollector("disk", false, newDiskCollector)
+}
+
+type diskCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	properties        propertyDescs
+	status            *stateSet
+}
+
+// newDiskCollector returns a new Collector of the physical drives inventory and health,
+// filtered by storage system name
+func newDiskCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	properties, err := newPropertyDescs(config.Metrics.Disks, config.Locale(), diskLabels)
+	if err != nil {
+		return nil, err
+	}
+	status := newStateSet(prometheus.BuildFQName(namespace, "disk", "status"), "Status of the disk.",
+		append(append([]string{}, diskLabels...), "status"), "normal", "warning", "error", "unknown")
+
+	return &diskCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		properties:        properties,
+		status:            status,
+	}, nil
+}
+
+func (c *diskCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.properties.describe(ch)
+	c.status.describe(ch)
+	ch <- diskInfo
+	ch <- diskSpare
+	ch <- diskInUse
+}
+
+func (c *diskCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromDisks(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "disk", err)
+		return err
+	}
+
+	for _, diskMetrics := range collectedMetrics.Metrics {
+		disk := diskMetrics.Disk
+		labelValues := []string{strings.TrimSpace(disk.Name), disk.ID, strings.TrimSpace(diskMetrics.Storage.Name)}
+		use := strings.ToLower(strings.TrimSpace(disk.Use))
+
+		ch <- prometheus.MustNewConstMetric(diskInfo, prometheus.GaugeValue, 1, append(labelValues,
+			strings.TrimSpace(disk.Tier), strings.TrimSpace(disk.Class), use, strings.TrimSpace(disk.Enclosure),
+			strings.TrimSpace(disk.Slot), strings.TrimSpace(disk.Vendor), strings.TrimSpace(disk.Model))...)
+		c.status.collect(ch, disk.Status, labelValues...)
+		c.properties.collect(ch, c.logger, disk, labelValues...)
+
+		// the use of the drives is member, spare, candidate, unused or failed
+		ch <- prometheus.MustNewConstMetric(diskSpare, prometheus.GaugeValue, boolValue(use == "spare"),
+			labelValues...)
+		ch <- prometheus.MustNewConstMetric(diskInUse, prometheus.GaugeValue, boolValue(use == "member"),
+			labelValues...)
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "disk")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "disk")
+
+	return nil
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/quantity"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	fabricInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "fabric", "info"),
+		"Fabric Info.",
+		[]string{"fabric", "fabric_wwn", "vendor", "principal_switch", "virtual_fabric"},
+		nil,
+	)
+
+	fabricSwitches = prometheus.NewDesc(prometheus.BuildFQName(namespace, "fabric", "switches"),
+		"Number of switches of the fabric.",
+		[]string{"fabric"},
+		nil,
+	)
+
+	fabricPorts = prometheus.NewDesc(prometheus.BuildFQName(namespace, "fabric", "ports"),
+		"Number of ports of the fabric.",
+		[]string{"fabric"},
+		nil,
+	)
+
+	fabricSwitchInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "fabric", "switch_info"),
+		"Switches member of the fabric.",
+		[]string{"fabric", "switch"},
+		nil,
+	)
+)
+
+func init() {
+	registerCollector("fabric", false, newFabricCollector)
+}
+
+type fabricCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	status            *stateSet
+	locale            quantity.Locale
+}
+
+// newFabricCollector returns a new Collector of the fabrics inventory and membership, filtered by fabric name
+func newFabricCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	status := newStateSet(prometheus.BuildFQName(namespace, "fabric", "status"), "Status of the fabric.",
+		[]string{"fabric", "status"}, "normal", "warning", "error", "unknown")
+
+	return &fabricCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		status:            status,
+		locale:            config.Locale(),
+	}, nil
+}
+
+func (c *fabricCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.status.describe(ch)
+	ch <- fabricInfo
+	ch <- fabricSwitches
+	ch <- fabricPorts
+	ch <- fabricSwitchInfo
+}
+
+func (c *fabricCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromFabrics(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "fabric", err)
+		return err
+	}
+
+	for _, fabricMetrics := range collectedMetrics.Metrics {
+		fabric := fabricMetrics.Fabric
+		name := strings.TrimSpace(fabric.Name)
+
+		ch <- prometheus.MustNewConstMetric(fabricInfo, prometheus.GaugeValue, 1, name, strings.TrimSpace(fabric.WWN),
+			strings.TrimSpace(fabric.Vendor), strings.TrimSpace(fabric.PrincipalSwitch),
+			strings.TrimSpace(fabric.VirtualFabric))
+		c.status.collect(ch, fabric.Status, name)
+
+		for desc, count := range map[*prometheus.Desc]string{fabricSwitches: fabric.Switches, fabricPorts: fabric.Ports} {
+			value, err := c.locale.Parse(count, "")
+			switch {
+			case err == nil:
+				ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, name)
+			case err != quantity.ErrNoValue:
+				c.logger.Errorf("Error converting the counts of fabric %s. %v", name, err)
+			}
+		}
+
+		for _, s := range fabricMetrics.Switches {
+			ch <- prometheus.MustNewConstMetric(fabricSwitchInfo, prometheus.GaugeValue, 1, name,
+				strings.TrimSpace(s.Name))
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "fabric")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "fabric")
+
+	return nil
+}
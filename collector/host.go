@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/quantity"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	hostLabels = []string{"host", "host_id"}
+
+	hostInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "host", "info"),
+		"Host Info.",
+		[]string{"host", "host_id", "os_type", "os_version", "ip_address"},
+		nil,
+	)
+
+	hostPorts = prometheus.NewDesc(prometheus.BuildFQName(namespace, "host", "ports"),
+		"Number of ports of the host.",
+		hostLabels,
+		nil,
+	)
+
+	hostVolumeMapping = prometheus.NewDesc(prometheus.BuildFQName(namespace, "host", "volume_mapping_info"),
+		"Volumes mapped to the host, joinable with the volume series on volume_uid.",
+		[]string{"host", "host_id", "volume_id", "volume_uid", "storage_system"},
+		nil,
+	)
+)
+
+func init() {
+	registerCollector("host", false, newHostCollector)
+}
+
+type hostCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	status            *stateSet
+	locale            quantity.Locale
+}
+
+// newHostCollector returns a new Collector of the hosts inventory, performance and volume mappings,
+// filtered by host name
+func newHostCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(hostLabels, config.Metrics.Hosts)
+	status := newStateSet(prometheus.BuildFQName(namespace, "host", "status"), "Status of the host.",
+		[]string{"host", "host_id", "status"}, "normal", "warning", "error", "unreachable", "unknown")
+
+	return &hostCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		status:            status,
+		locale:            config.Locale(),
+	}, nil
+}
+
+func (c *hostCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	c.status.describe(ch)
+	ch <- hostInfo
+	ch <- hostPorts
+	ch <- hostVolumeMapping
+}
+
+func (c *hostCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromHosts(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "host", err)
+		return err
+	}
+
+	for _, hostMetrics := range collectedMetrics.Metrics {
+		host := hostMetrics.Host
+		name := strings.TrimSpace(host.Name)
+
+		ch <- prometheus.MustNewConstMetric(hostInfo, prometheus.GaugeValue, 1, name, host.ID,
+			strings.TrimSpace(host.OSType), strings.TrimSpace(host.OSVersion), strings.TrimSpace(host.IPAddress))
+		c.status.collect(ch, host.Status, name, host.ID)
+
+		ports, err := c.locale.Parse(host.Ports, "")
+		switch {
+		case err == nil:
+			ch <- prometheus.MustNewConstMetric(hostPorts, prometheus.GaugeValue, ports, name, host.ID)
+		case err != quantity.ErrNoValue:
+			c.logger.Errorf("Error converting the ports of host %s. %v", name, err)
+		}
+
+		for _, metricValue := range hostMetrics.HostMetrics {
+			if metric := c.metrics.metric(metricValue, name, host.ID); metric != nil {
+				ch <- metric
+			}
+		}
+
+		for _, volume := range hostMetrics.Volumes {
+			ch <- prometheus.MustNewConstMetric(hostVolumeMapping, prometheus.GaugeValue, 1, name, host.ID,
+				volume.ID, volume.VolumeUniqueID, strings.TrimSpace(volume.StorageSystem))
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "host")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "host")
+
+	return nil
+}
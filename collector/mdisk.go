@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	// mdiskLabels label the managed disk and rank metrics
+	mdiskLabels = []string{"name", "type", "mdisk_id", "pool", "storage_system"}
+
+	mdiskInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "managed_disk", "info"),
+		"Managed Disk Info.",
+		append(append([]string{}, mdiskLabels...), "raid_level", "tier", "mode"),
+		nil,
+	)
+)
+
+func init() {
+	registerCollector("mdisk", false, newManagedDiskCollector)
+}
+
+type managedDiskCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	properties        propertyDescs
+	status            *stateSet
+}
+
+// newManagedDiskCollector returns a new Collector of the managed disks, arrays and ranks performance and inventory,
+// filtered by storage system name
+func newManagedDiskCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(mdiskLabels, config.Metrics.ManagedDisks.Metrics)
+	properties, err := newPropertyDescs(config.Metrics.ManagedDisks.PropertiesConfig, config.Locale(), mdiskLabels)
+	if err != nil {
+		return nil, err
+	}
+	status := newStateSet(prometheus.BuildFQName(namespace, "managed_disk", "status"),
+		"Status of the managed disk.", append(append([]string{}, mdiskLabels...), "status"),
+		"normal", "warning", "error", "unknown")
+
+	return &managedDiskCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		properties:        properties,
+		status:            status,
+	}, nil
+}
+
+func (c *managedDiskCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	c.properties.describe(ch)
+	c.status.describe(ch)
+	ch <- mdiskInfo
+}
+
+func (c *managedDiskCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromManagedDisks(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "mdisk", err)
+		return err
+	}
+
+	for _, managedDiskMetrics := range collectedMetrics.Metrics {
+		managedDisk := managedDiskMetrics.ManagedDisk
+		labelValues := []string{strings.TrimSpace(managedDisk.Name), managedDiskMetrics.Type, managedDisk.ID,
+			strings.TrimSpace(managedDisk.Pool), strings.TrimSpace(managedDiskMetrics.Storage.Name)}
+
+		ch <- prometheus.MustNewConstMetric(mdiskInfo, prometheus.GaugeValue, 1, append(labelValues,
+			strings.TrimSpace(managedDisk.RAIDLevel), strings.TrimSpace(managedDisk.Tier),
+			strings.TrimSpace(managedDisk.Mode))...)
+		c.status.collect(ch, managedDisk.Status, labelValues...)
+		c.properties.collect(ch, c.logger, managedDisk, labelValues...)
+
+		for _, metricValue := range managedDiskMetrics.ManagedDiskMetrics {
+			if metric := c.metrics.metric(metricValue, labelValues...); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "mdisk")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "mdisk")
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// nodeLabels label the node and I/O group performance metrics, the metrics of the nodes section being
+// exported for both
+var nodeLabels = []string{"name", "type", "io_group", "storage_system"}
+
+func init() {
+	registerCollector("node", false, newNodeCollector)
+}
+
+type nodeCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	ioGroupMetrics    *metricDescs
+}
+
+// newNodeCollector returns a new Collector of the nodes and I/O groups performance, filtered by storage system name
+func newNodeCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(nodeLabels, config.Metrics.Nodes)
+	ioGroupMetrics := newMetricDescs(nodeLabels, config.Metrics.IOGroups)
+
+	return &nodeCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		ioGroupMetrics:    ioGroupMetrics,
+	}, nil
+}
+
+func (c *nodeCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	// a metric may be exported for both the nodes and the I/O groups, told apart by the type label
+	described := make(map[string]bool)
+	for _, desc := range c.metrics.descs {
+		described[desc.String()] = true
+	}
+	for _, desc := range c.ioGroupMetrics.descs {
+		if !described[desc.String()] {
+			ch <- desc
+		}
+	}
+}
+
+func (c *nodeCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromNodes(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "node", err)
+		return err
+	}
+
+	for _, nodeMetrics := range collectedMetrics.Metrics {
+		node := nodeMetrics.Node
+		for _, metricValue := range nodeMetrics.NodeMetrics {
+			if metric := c.metrics.metric(metricValue, strings.TrimSpace(node.Name), "node",
+				strings.TrimSpace(node.IOGroup), strings.TrimSpace(nodeMetrics.Storage.Name)); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+
+	for _, ioGroupMetrics := range collectedMetrics.IOGroupMetrics {
+		name := strings.TrimSpace(ioGroupMetrics.IOGroup.Name)
+		for _, metricValue := range ioGroupMetrics.IOGroupMetrics {
+			if metric := c.ioGroupMetrics.metric(metricValue, name, "ioGroup", name,
+				strings.TrimSpace(ioGroupMetrics.Storage.Name)); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "node")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "node")
+
+	return nil
+}
@@ -1,8 +1,7 @@
 package collector
 
 import (
-	"reflect"
-	"strconv"
+	"context"
 	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,68 +11,77 @@ import (
 	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
 )
 
+var (
+	// poolLabels label the pool metrics
+	poolLabels = []string{"pool_name", "storage_system"}
+
+	poolInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "pool", "info"),
+		"Pool Info.",
+		append(append([]string{}, poolLabels...), "tier", "easy_tier", "encryption", "raid_level",
+			"extent_size", "backend_storage_system_type", "parent_name"),
+		nil,
+	)
+)
+
 func init() {
 	registerCollector("pool", true, newPoolCollector)
 }
 
 type poolCollector struct {
-	ibmSpectrumClient spectrumservice.Client
+	ibmSpectrumClient spectrumservice.API
 	logger            *zap.SugaredLogger
-	properties        map[string]*prometheus.Desc
+	filter            string
+	properties        propertyDescs
+	status            *stateSet
 }
 
 // newPoolCollector returns a new Collector Pools information
 func newPoolCollector(config monitoring.MetricsConfig, logger *zap.Logger,
-	spectrumClient spectrumservice.Client) (Collector, error) {
-
-	labelPool := []string{"pool_name", "storage_system"}
-
-	properties := make(map[string]*prometheus.Desc)
-
-	for _, p := range config.Metrics.Pools.Properties {
-		properties[p.PropertyName] = prometheus.NewDesc(p.PrometheusName, p.PrometheusHelp, labelPool, nil)
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	properties, err := newPropertyDescs(config.Metrics.Pools, config.Locale(), poolLabels)
+	if err != nil {
+		return nil, err
 	}
+	status := newStateSet(prometheus.BuildFQName(namespace, "pool", "status"), "Status of the pool.",
+		append(append([]string{}, poolLabels...), "status"), "normal", "warning", "error", "unknown")
 
 	return &poolCollector{
 		ibmSpectrumClient: spectrumClient,
 		logger:            logger.Sugar(),
+		filter:            filter,
 		properties:        properties,
+		status:            status,
 	}, nil
 }
 
 func (c *poolCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
-	for _, desc := range c.properties {
-		ch <- desc
-	}
+	c.properties.describe(ch)
+	c.status.describe(ch)
+	ch <- poolInfo
 }
 
-func (c *poolCollector) Update(ch chan<- prometheus.Metric) error {
-	collectedMetrics, err := c.ibmSpectrumClient.CollectFromPools(*Filter["pool"])
-	if err != nil || collectedMetrics == nil {
-		c.logger.Error("Error getting Pools", err)
+func (c *poolCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromPools(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "pool", err)
 		return err
 	}
+	if collectedMetrics == nil {
+		c.logger.Error("Error getting Pools")
+		return nil
+	}
 
 	spectrumMetrics := collectedMetrics.Metrics
 
 	for _, poolMetrics := range spectrumMetrics {
 		p := poolMetrics.Pool
-		t := reflect.TypeOf(p)
-		v := reflect.ValueOf(p)
-
-		for i := 0; i < t.NumField(); i++ {
-			f := t.Field(i)
-
-			if x, found := c.properties[f.Tag.Get("json")]; found && v.Field(i).String() != "" {
-				value, err := strconv.ParseFloat(strings.ReplaceAll(v.Field(i).String(), ",", ""), 64)
+		c.properties.collect(ch, c.logger, p, p.Name, p.StorageSystem)
 
-				if err == nil {
-					ch <- prometheus.MustNewConstMetric(x, prometheus.GaugeValue, value, p.Name, p.StorageSystem)
-				} else {
-					c.logger.Error("Error converting values.", err)
-				}
-			}
-		}
+		ch <- prometheus.MustNewConstMetric(poolInfo, prometheus.GaugeValue, 1, p.Name, p.StorageSystem,
+			strings.TrimSpace(p.Tier), strings.TrimSpace(p.EasyTier),
+			strings.TrimSpace(p.Encryption), strings.TrimSpace(p.RAIDLevel), strings.TrimSpace(p.ExtentSize),
+			strings.TrimSpace(p.BackEndStorageSystemType), strings.TrimSpace(p.ParentName))
+		c.status.collect(ch, p.Status, p.Name, p.StorageSystem)
 	}
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "pool")
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "pool")
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// portLabels label the storage system port metrics
+var portLabels = []string{"port", "port_id", "wwpn", "node", "speed", "storage_system"}
+
+func init() {
+	registerCollector("port", false, newPortCollector)
+}
+
+type portCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	status            *stateSet
+}
+
+// newPortCollector returns a new Collector of the storage system ports performance and status,
+// filtered by storage system name
+func newPortCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(portLabels, config.Metrics.Ports)
+	status := newStateSet(prometheus.BuildFQName(namespace, "port", "status"), "Status of the storage system port.",
+		append(append([]string{}, portLabels...), "status"), "normal", "warning", "error", "unknown")
+
+	return &portCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		status:            status,
+	}, nil
+}
+
+func (c *portCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	c.status.describe(ch)
+}
+
+func (c *portCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromPorts(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "port", err)
+		return err
+	}
+
+	for _, portMetrics := range collectedMetrics.Metrics {
+		port := portMetrics.Port
+		labelValues := []string{strings.TrimSpace(port.Name), port.ID, strings.TrimSpace(port.WWPN),
+			strings.TrimSpace(port.Node), strings.TrimSpace(port.Speed), strings.TrimSpace(portMetrics.Storage.Name)}
+
+		c.status.collect(ch, port.Status, labelValues...)
+		for _, metricValue := range portMetrics.PortMetrics {
+			if metric := c.metrics.metric(metricValue, labelValues...); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "port")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "port")
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/quantity"
+)
+
+// propertyDescs are the descriptions of the properties of a resource type, by IBM Spectrum property name.
+// A property may be exported by several metrics, on its own and as part of property groups.
+type propertyDescs map[string][]propertyDesc
+
+type propertyDesc struct {
+	desc *prometheus.Desc
+	// unit of the values given without unit
+	unit string
+	// locale formatting the values
+	locale quantity.Locale
+	// labelValues are appended to the labels of the resource, the value of the group label
+	labelValues []string
+}
+
+// newPropertyDescs returns the descriptions of the properties, the labels of the resources being checked against the
+// labels of the property groups
+func newPropertyDescs(config monitoring.PropertiesConfig, locale quantity.Locale, labels []string) (propertyDescs,
+	error) {
+	descs := make(propertyDescs)
+	for _, p := range config.Properties {
+		descs[p.PropertyName] = append(descs[p.PropertyName], propertyDesc{
+			desc:   prometheus.NewDesc(p.PrometheusName, p.PrometheusHelp, labels, nil),
+			unit:   p.Unit,
+			locale: locale,
+		})
+	}
+	for _, g := range config.PropertyGroups {
+		for _, label := range labels {
+			if g.Label == label {
+				return nil, fmt.Errorf("label %s of the property group %s is already a label of the resource",
+					g.Label, g.PrometheusName)
+			}
+		}
+		desc := prometheus.NewDesc(g.PrometheusName, g.PrometheusHelp,
+			append(append([]string{}, labels...), g.Label), nil)
+		for _, p := range g.Properties {
+			descs[p.PropertyName] = append(descs[p.PropertyName], propertyDesc{
+				desc:        desc,
+				unit:        g.Unit,
+				locale:      locale,
+				labelValues: []string{p.LabelValue},
+			})
+		}
+	}
+	return descs, nil
+}
+
+func (d propertyDescs) describe(ch chan<- *prometheus.Desc) {
+	// the properties of a group share the description of the group
+	described := make(map[*prometheus.Desc]bool)
+	for _, properties := range d {
+		for _, property := range properties {
+			if !described[property.desc] {
+				described[property.desc] = true
+				ch <- property.desc
+			}
+		}
+	}
+}
+
+// collect exports the configured properties of a resource, the fields of the resource being matched
+// to the properties by their json name
+func (d propertyDescs) collect(ch chan<- prometheus.Metric, logger *zap.SugaredLogger, resource interface{},
+	labelValues ...string) {
+	t := reflect.TypeOf(resource)
+	v := reflect.ValueOf(resource)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		for _, x := range d[f.Tag.Get("json")] {
+			value, err := propertyValue(v.Field(i).String(), x.unit, x.locale)
+
+			switch {
+			case err == nil:
+				ch <- prometheus.MustNewConstMetric(x.desc, prometheus.GaugeValue, value,
+					append(append([]string{}, labelValues...), x.labelValues...)...)
+			case err == quantity.ErrNoValue:
+				// the property is not available for this resource
+			default:
+				logger.Errorf("Error converting the value of %s. %v", f.Tag.Get("json"), err)
+			}
+		}
+	}
+}
+
+// propertyValue parses the value of a property, a quantity converted to the base unit or Yes/No
+func propertyValue(property, unit string, locale quantity.Locale) (float64, error) {
+	switch strings.ToLower(strings.TrimSpace(property)) {
+	case "yes":
+		return 1, nil
+	case "no":
+		return 0, nil
+	}
+	return locale.Parse(property, unit)
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// stateSet exports an IBM Spectrum status as one series per possible state, the series of the current
+// state being 1 and the others 0
+type stateSet struct {
+	desc   *prometheus.Desc
+	states []string
+}
+
+// newStateSet returns a state set, the last label being the state.
+// The last state is used for the values not in the list.
+func newStateSet(name, help string, labels []string, states ...string) *stateSet {
+	return &stateSet{
+		desc:   prometheus.NewDesc(name, help, labels, nil),
+		states: states,
+	}
+}
+
+func (s *stateSet) describe(ch chan<- *prometheus.Desc) {
+	ch <- s.desc
+}
+
+// collect exports the series of all the states, the value being matched case insensitively and its spaces
+// replaced by underscores, e.g. Never Run is the never_run state
+func (s *stateSet) collect(ch chan<- prometheus.Metric, value string, labelValues ...string) {
+	current := strings.Join(strings.Fields(strings.ToLower(value)), "_")
+	known := false
+	for _, state := range s.states {
+		known = known || state == current
+	}
+	if !known {
+		current = s.states[len(s.states)-1]
+	}
+
+	for _, state := range s.states {
+		v := 0.0
+		if state == current {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, v,
+			append(append([]string{}, labelValues...), state)...)
+	}
+}
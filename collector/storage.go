@@ -1,7 +1,8 @@
 package collector
 
 import (
-	"strings"
+	"context"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -12,11 +13,23 @@ import (
 )
 
 var (
+	// storageLabels label the storage system performance metrics
+	storageLabels = []string{"name", "type", "storage_name"}
+
 	svcInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "system", "info"),
 		"Storage System Info.",
 		[]string{"type", "model", "name", "firmware", "ip_address"},
 		nil,
 	)
+
+	storageSampleAge = prometheus.NewDesc(prometheus.BuildFQName(namespace, "system", "performance_sample_age_seconds"),
+		"Age of the newest performance sample of the storage system, growing when its performance monitor stops.",
+		[]string{"storage_system"},
+		nil,
+	)
+
+	// now is the time of the scrape, replaced by the tests
+	now = time.Now
 )
 
 func init() {
@@ -24,84 +37,122 @@ func init() {
 }
 
 type storageCollector struct {
-	ibmSpectrumClient spectrumservice.Client
+	ibmSpectrumClient spectrumservice.API
 	logger            *zap.SugaredLogger
-	metrics           map[int]*prometheus.Desc
+	filter            string
+	metrics           *metricDescs
+	properties        propertyDescs
+	dataCollection    *stateSet
+	probeStatus       *stateSet
+
+	// newestSamples are the times of the newest performance samples received for each storage system, kept
+	// across the scrapes so that the age keeps growing once the samples are out of the requested time range
+	mutex         sync.Mutex
+	newestSamples map[string]time.Time
 }
 
 // newPoolCollector returns a new Collector Pools information
 func newStorageCollector(config monitoring.MetricsConfig, logger *zap.Logger,
-	spectrumClient spectrumservice.Client) (Collector, error) {
-	labelNames := []string{"name", "type", "storage_name"}
-
-	metrics := make(map[int]*prometheus.Desc)
-
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
 	//transform the config into prometheus desc
-	for _, metric := range config.Metrics.StorageSystems {
-		metrics[metric.MetricID] = prometheus.NewDesc(metric.PrometheusName, metric.PrometheusHelp, labelNames, nil)
-	}
-
-	for _, metric := range config.Metrics.StorageSystemsAndVolumes {
-		metrics[metric.MetricID] = prometheus.NewDesc(metric.PrometheusName, metric.PrometheusHelp, labelNames, nil)
+	metrics := newMetricDescs(storageLabels, config.Metrics.StorageSystems.Metrics,
+		config.Metrics.StorageSystemsAndVolumes)
+	properties, err := newPropertyDescs(config.Metrics.StorageSystems.PropertiesConfig, config.Locale(),
+		[]string{"storage_system"})
+	if err != nil {
+		return nil, err
 	}
+	dataCollection := newStateSet(prometheus.BuildFQName(namespace, "system", "data_collection_status"),
+		"Status of the data collection of the storage system by IBM Spectrum.",
+		[]string{"storage_system", "status"}, "normal", "warning", "error", "disabled", "unknown")
+	probeStatus := newStateSet(prometheus.BuildFQName(namespace, "system", "probe_status"),
+		"Status of the last probe of the storage system by IBM Spectrum.",
+		[]string{"storage_system", "status"}, "successful", "warning", "failed", "running", "never_run", "unknown")
 
 	return &storageCollector{
 		ibmSpectrumClient: spectrumClient,
 		logger:            logger.Sugar(),
+		filter:            filter,
 		metrics:           metrics,
+		properties:        properties,
+		dataCollection:    dataCollection,
+		probeStatus:       probeStatus,
+		newestSamples:     make(map[string]time.Time),
 	}, nil
 }
 
 func (c *storageCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
-	for _, desc := range c.metrics {
-		ch <- desc
-	}
+	c.metrics.describe(ch)
+	c.properties.describe(ch)
+	c.dataCollection.describe(ch)
+	c.probeStatus.describe(ch)
 	ch <- svcInfo
+	ch <- storageSampleAge
 }
 
-func (c *storageCollector) Update(ch chan<- prometheus.Metric) error {
-	collectedMetrics, err := c.ibmSpectrumClient.CollectFromStorage("storage")
+func (c *storageCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromStorage(ctx, c.filter)
 	if err != nil {
-		c.logger.Error("Error during authentication.", err)
-		ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 0)
+		collectionFailed(ch, c.logger, "storage", err)
 		return err
 	}
 
 	spectrumMetrics := collectedMetrics.Metrics
 
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	listed := make(map[string]bool, len(spectrumMetrics))
+
 	for _, spectrumMetric := range spectrumMetrics {
 		for _, storageMetric := range spectrumMetric.StorageSystemMetrics {
-			for x := 1; x <= len(storageMetric.Current); x++ {
-				// get the latest available metric
-				current := storageMetric.Current[len(storageMetric.Current)-x]
-				if current.Y != nil {
-					ch <- prometheus.NewMetricWithTimestamp(time.Unix(0, current.X*int64(time.Millisecond)),
-						prometheus.MustNewConstMetric(c.metrics[storageMetric.MetricID],
-							prometheus.GaugeValue, *current.Y, storageMetric.DeviceName, "storageSystem", ""))
-					break
-				}
-			}
-		}
-
-		for _, volumeMetrics := range spectrumMetric.VolumeMetrics {
-			for x := 1; x <= len(volumeMetrics.Current); x++ {
-				// get the latest available metric
-				current := volumeMetrics.Current[len(volumeMetrics.Current)-x]
-				if current.Y != nil {
-					ch <- prometheus.NewMetricWithTimestamp(time.Unix(0, current.X*int64(time.Millisecond)),
-						prometheus.MustNewConstMetric(c.metrics[volumeMetrics.MetricID],
-							prometheus.GaugeValue, *current.Y, strings.TrimSpace(volumeMetrics.DeviceName), "volume",
-							strings.TrimSpace(volumeMetrics.ParentDeviceName)))
-					break
-				}
+			if metric := c.metrics.metric(storageMetric, storageMetric.DeviceName, "storageSystem",
+				spectrumMetric.Storage.Name); metric != nil {
+				ch <- metric
 			}
 		}
+		c.properties.collect(ch, c.logger, spectrumMetric.Storage, spectrumMetric.Storage.Name)
 		ch <- prometheus.MustNewConstMetric(svcInfo, prometheus.GaugeValue, 1, spectrumMetric.Storage.Type,
 			spectrumMetric.Storage.Model, spectrumMetric.Storage.Name, spectrumMetric.Storage.Firmware,
 			spectrumMetric.Storage.IPAddress)
+		c.dataCollection.collect(ch, spectrumMetric.Storage.DataCollection, spectrumMetric.Storage.Name)
+		c.probeStatus.collect(ch, spectrumMetric.Storage.ProbeStatus, spectrumMetric.Storage.Name)
+
+		// the storage systems whose performance request failed are listed without samples, their age keeps growing
+		listed[spectrumMetric.Storage.ID] = true
+		newest, found := c.newestSamples[spectrumMetric.Storage.ID]
+		if sample, received := newestSample(spectrumMetric.StorageSystemMetrics); received && sample.After(newest) {
+			newest, found = sample, true
+			c.newestSamples[spectrumMetric.Storage.ID] = newest
+		}
+		if found {
+			ch <- prometheus.MustNewConstMetric(storageSampleAge, prometheus.GaugeValue, now().Sub(newest).Seconds(),
+				spectrumMetric.Storage.Name)
+		}
+	}
+	// the storage systems no longer listed by IBM Spectrum are forgotten
+	for id := range c.newestSamples {
+		if !listed[id] {
+			delete(c.newestSamples, id)
+		}
 	}
+
 	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "storage")
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "storage")
 
 	return nil
 }
+
+// newestSample returns the time of the newest performance sample with a value, false when there is none
+func newestSample(metricValues []spectrumservice.MetricValue) (time.Time, bool) {
+	var newest int64
+	found := false
+	for _, metricValue := range metricValues {
+		for _, current := range metricValue.Current {
+			if current.Y != nil && (!found || current.X > newest) {
+				newest = current.X
+				found = true
+			}
+		}
+	}
+	return time.Unix(0, newest*int64(time.Millisecond)), found
+}
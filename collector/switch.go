@@ -1,69 +1,105 @@
 package collector
 
 import (
-	"time"
+	"context"
+	"strings"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/quantity"
 	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
 )
 
+var (
+	switchInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "switch", "info"),
+		"Switch Info.",
+		[]string{"name", "vendor", "model", "firmware", "fabric", "principal_switch_of_fabric", "ip_address",
+			"serial_number", "wwn"},
+		nil,
+	)
+
+	switchLastSuccessfulProbe = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "switch", "last_successful_probe_timestamp_seconds"),
+		"Time of the last successful probe of the switch by IBM Spectrum.",
+		[]string{"name"},
+		nil,
+	)
+
+	switchLastSuccessfulMonitor = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "switch", "last_successful_monitor_timestamp_seconds"),
+		"Time of the last successful performance monitoring of the switch by IBM Spectrum.",
+		[]string{"name"},
+		nil,
+	)
+)
+
 func init() {
 	registerCollector("switch", true, newSwitchCollector)
 }
 
 type switchCollector struct {
-	ibmSpectrumClient spectrumservice.Client
+	ibmSpectrumClient spectrumservice.API
 	logger            *zap.SugaredLogger
-	metrics           map[int]*prometheus.Desc
+	filter            string
+	metrics           *metricDescs
+	status            *stateSet
+	probeStatus       *stateSet
+	monitorStatus     *stateSet
 }
 
 // newPoolCollector returns a new Collector Pools information
 func newSwitchCollector(config monitoring.MetricsConfig, logger *zap.Logger,
-	spectrumClient spectrumservice.Client) (Collector, error) {
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
 	labelNameSwitch := []string{"name"}
 
-	metrics := make(map[int]*prometheus.Desc)
-
-	for _, metric := range config.Metrics.Switches {
-		metrics[metric.MetricID] = prometheus.NewDesc(metric.PrometheusName, metric.PrometheusHelp, labelNameSwitch, nil)
-	}
+	metrics := newMetricDescs(labelNameSwitch, config.Metrics.Switches)
+	status := newStateSet(prometheus.BuildFQName(namespace, "switch", "status"), "Status of the switch.",
+		[]string{"name", "status"}, "normal", "warning", "error", "unreachable", "unknown")
+	probeStatus := newStateSet(prometheus.BuildFQName(namespace, "switch", "probe_status"),
+		"Status of the last probe of the switch by IBM Spectrum.",
+		[]string{"name", "status"}, "successful", "warning", "failed", "running", "never_run", "unknown")
+	monitorStatus := newStateSet(prometheus.BuildFQName(namespace, "switch", "monitor_status"),
+		"Status of the performance monitor of the switch in IBM Spectrum.",
+		[]string{"name", "status"}, "running", "warning", "failed", "stopped", "disabled", "never_run",
+		"unknown")
 
 	return &switchCollector{
 		ibmSpectrumClient: spectrumClient,
 		logger:            logger.Sugar(),
+		filter:            filter,
 		metrics:           metrics,
+		status:            status,
+		probeStatus:       probeStatus,
+		monitorStatus:     monitorStatus,
 	}, nil
 }
 
 func (c *switchCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
-	for _, desc := range c.metrics {
-		ch <- desc
-	}
+	c.metrics.describe(ch)
+	c.status.describe(ch)
+	c.probeStatus.describe(ch)
+	c.monitorStatus.describe(ch)
+	ch <- switchInfo
+	ch <- switchLastSuccessfulProbe
+	ch <- switchLastSuccessfulMonitor
 }
 
-func (c *switchCollector) Update(ch chan<- prometheus.Metric) error {
-	collectedMetrics, err := c.ibmSpectrumClient.CollectFromSwitch("switch")
+func (c *switchCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromSwitch(ctx, c.filter)
 	if err != nil {
-		c.logger.Error("Error during authentication.", err)
+		collectionFailed(ch, c.logger, "switch", err)
 		return nil
 	}
 
 	spectrumMetrics := collectedMetrics.Metrics
 
 	for _, spectrumMetric := range spectrumMetrics {
+		c.collectInventory(ch, spectrumMetric.Switch)
 		for _, switchMetric := range spectrumMetric.SwitchAggregatedMetrics {
-			for x := 1; x <= len(switchMetric.Current); x++ {
-				// get the latest available metric
-				current := switchMetric.Current[len(switchMetric.Current)-x]
-				if current.Y != nil {
-					ch <- prometheus.NewMetricWithTimestamp(time.Unix(0, current.X*int64(time.Millisecond)),
-						prometheus.MustNewConstMetric(c.metrics[switchMetric.MetricID],
-							prometheus.GaugeValue, *current.Y, switchMetric.DeviceName))
-					break
-				}
+			if metric := c.metrics.metric(switchMetric, switchMetric.DeviceName); metric != nil {
+				ch <- metric
 			}
 		}
 	}
@@ -71,3 +107,29 @@ func (c *switchCollector) Update(ch chan<- prometheus.Metric) error {
 	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "switch")
 	return nil
 }
+
+// collectInventory exports the descriptive fields of a switch and its monitoring status in IBM Spectrum
+func (c *switchCollector) collectInventory(ch chan<- prometheus.Metric, s spectrumservice.Switch) {
+	name := strings.TrimSpace(s.Name)
+
+	ch <- prometheus.MustNewConstMetric(switchInfo, prometheus.GaugeValue, 1, name, strings.TrimSpace(s.Vendor),
+		strings.TrimSpace(s.Model), strings.TrimSpace(s.Firmware), strings.TrimSpace(s.Fabric),
+		strings.TrimSpace(s.PrincipalSwitchOfFabric), strings.TrimSpace(s.IPAddress),
+		strings.TrimSpace(s.SerialNumber), strings.TrimSpace(s.WWN))
+	c.status.collect(ch, s.Status, name)
+	c.probeStatus.collect(ch, s.ProbeStatus, name)
+	c.monitorStatus.collect(ch, s.PerformanceMonitorStatus, name)
+
+	for desc, value := range map[*prometheus.Desc]string{
+		switchLastSuccessfulProbe:   s.LastSuccessfulProbe,
+		switchLastSuccessfulMonitor: s.LastSuccessfulMonitor,
+	} {
+		timestamp, err := parseTimestamp(value)
+		switch {
+		case err == nil:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, timestamp, name)
+		case err != quantity.ErrNoValue:
+			c.logger.Errorf("Error converting the monitoring dates of switch %s. %v", name, err)
+		}
+	}
+}
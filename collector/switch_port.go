@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/quantity"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	// switchPortLabels label the switch port metrics
+	switchPortLabels = []string{"fabric", "switch", "port_index", "wwpn"}
+
+	switchPortInfo = prometheus.NewDesc(prometheus.BuildFQName(namespace, "switch_port", "info"),
+		"Switch Port Info.",
+		append(append([]string{}, switchPortLabels...), "port", "type", "speed"),
+		nil,
+	)
+
+	switchPortSpeed = prometheus.NewDesc(prometheus.BuildFQName(namespace, "switch_port", "speed_bits_per_second"),
+		"Speed of the switch port.",
+		switchPortLabels,
+		nil,
+	)
+)
+
+func init() {
+	registerCollector("switch_port", false, newSwitchPortCollector)
+}
+
+type switchPortCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	state             *stateSet
+	locale            quantity.Locale
+}
+
+// newSwitchPortCollector returns a new Collector of the switch ports performance, state and speed,
+// filtered by switch name
+func newSwitchPortCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(switchPortLabels, config.Metrics.SwitchPorts)
+	state := newStateSet(prometheus.BuildFQName(namespace, "switch_port", "state"), "State of the switch port.",
+		append(append([]string{}, switchPortLabels...), "state"), "online", "offline", "unknown")
+
+	return &switchPortCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		state:             state,
+		locale:            config.Locale(),
+	}, nil
+}
+
+func (c *switchPortCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	c.state.describe(ch)
+	ch <- switchPortInfo
+	ch <- switchPortSpeed
+}
+
+func (c *switchPortCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromSwitchPorts(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "switch_port", err)
+		return err
+	}
+
+	for _, switchPortMetrics := range collectedMetrics.Metrics {
+		port := switchPortMetrics.Port
+		labelValues := []string{strings.TrimSpace(switchPortMetrics.Switch.Fabric),
+			strings.TrimSpace(switchPortMetrics.Switch.Name), strings.TrimSpace(port.PortIndex),
+			strings.TrimSpace(port.WWPN)}
+
+		ch <- prometheus.MustNewConstMetric(switchPortInfo, prometheus.GaugeValue, 1, append(labelValues,
+			strings.TrimSpace(port.Name), strings.TrimSpace(port.Type), strings.TrimSpace(port.Speed))...)
+		c.state.collect(ch, port.State, labelValues...)
+
+		// the speeds given without unit are in Gbps
+		speed, err := c.locale.Parse(port.Speed, "Gbps")
+		switch {
+		case err == nil:
+			ch <- prometheus.MustNewConstMetric(switchPortSpeed, prometheus.GaugeValue, speed, labelValues...)
+		case err != quantity.ErrNoValue:
+			c.logger.Errorf("Error converting the speed of port %s of switch %s. %v", port.Name,
+				switchPortMetrics.Switch.Name, err)
+		}
+
+		for _, metricValue := range switchPortMetrics.SwitchPortMetrics {
+			if metric := c.metrics.metric(metricValue, labelValues...); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "switch_port")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "switch_port")
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/topine/ibm-spectrum-exporter/quantity"
+)
+
+// timestampLayouts are the formats of the dates returned by IBM Spectrum, the dates without time zone being local
+var timestampLayouts = []string{
+	"Jan 2, 2006, 15:04:05 MST",
+	"Jan 2, 2006, 15:04:05",
+	"Jan 2, 2006, 3:04:05 PM MST",
+	"Jan 2, 2006, 3:04:05 PM",
+	"Jan 2, 2006 3:04:05 PM MST",
+	"Jan 2, 2006 3:04:05 PM",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	time.RFC3339,
+	time.UnixDate,
+}
+
+// parseTimestamp parses a date into Unix seconds. The numeric dates are epochs in milliseconds, or in seconds
+// when too small. The placeholders such as N/A return quantity.ErrNoValue.
+func parseTimestamp(value string) (float64, error) {
+	if quantity.IsPlaceholder(value) {
+		return 0, quantity.ErrNoValue
+	}
+	value = strings.TrimSpace(value)
+
+	if epoch, err := strconv.ParseFloat(value, 64); err == nil {
+		// 1e11 seconds is in the year 5138, 1e11 milliseconds in 1973
+		if epoch > 1e11 {
+			return epoch / 1000, nil
+		}
+		return epoch, nil
+	}
+
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return float64(t.UnixNano()) / 1e9, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown date format %q", value)
+}
@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// volumeLabels label the volume metrics, the ids identifying a volume even when it is renamed
+var volumeLabels = []string{"name", "storage_system", "volume_id", "volume_uid", "pool"}
+
+func init() {
+	registerCollector("volume", true, newVolumeCollector)
+}
+
+type volumeCollector struct {
+	ibmSpectrumClient spectrumservice.API
+	logger            *zap.SugaredLogger
+	filter            string
+	metrics           *metricDescs
+	properties        propertyDescs
+}
+
+// newVolumeCollector returns a new Collector of the volumes performance and properties, filtered by volume name
+func newVolumeCollector(config monitoring.MetricsConfig, logger *zap.Logger,
+	spectrumClient spectrumservice.API, filter string) (Collector, error) {
+	metrics := newMetricDescs(volumeLabels, config.Metrics.Volumes.Metrics)
+	properties, err := newPropertyDescs(config.Metrics.Volumes.PropertiesConfig, config.Locale(), volumeLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &volumeCollector{
+		ibmSpectrumClient: spectrumClient,
+		logger:            logger.Sugar(),
+		filter:            filter,
+		metrics:           metrics,
+		properties:        properties,
+	}, nil
+}
+
+func (c *volumeCollector) UpdateDescribe(ch chan<- *prometheus.Desc) {
+	c.metrics.describe(ch)
+	c.properties.describe(ch)
+}
+
+func (c *volumeCollector) Update(ctx context.Context, ch chan<- prometheus.Metric) error {
+	collectedMetrics, err := c.ibmSpectrumClient.CollectFromVolumes(ctx, c.filter)
+	if err != nil {
+		collectionFailed(ch, c.logger, "volume", err)
+		return err
+	}
+
+	for _, volumeMetrics := range collectedMetrics.Metrics {
+		volume := volumeMetrics.Volume
+		name, storageSystem, pool := strings.TrimSpace(volume.Name), strings.TrimSpace(volumeMetrics.Storage.Name),
+			strings.TrimSpace(volume.Pool)
+
+		c.properties.collect(ch, c.logger, volume, name, storageSystem, volume.ID, volume.VolumeUniqueID, pool)
+		for _, metricValue := range volumeMetrics.VolumeMetrics {
+			if metric := c.metrics.metric(metricValue, name, storageSystem, volume.ID, volume.VolumeUniqueID,
+				pool); metric != nil {
+				ch <- metric
+			}
+		}
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, 1, "volume")
+	ch <- prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, collectedMetrics.CollectionDuration, "volume")
+
+	return nil
+}
@@ -0,0 +1,331 @@
+// Package discovery queries the metrics available on an IBM Spectrum server and generates the metrics
+// configuration file of the exporter.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// API is the part of the IBM Spectrum client used by the discovery
+type API interface {
+	StorageSystems(ctx context.Context) ([]spectrumservice.StorageSystem, error)
+	Switches(ctx context.Context) ([]spectrumservice.Switch, error)
+	StorageSystemCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	VolumeCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	PortCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	NodeCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	IOGroupCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	ManagedDiskCatalog(ctx context.Context, storageSystemID string) (map[int]spectrumservice.MetricDetail, error)
+	SwitchCatalog(ctx context.Context, switchID string) (map[int]spectrumservice.MetricDetail, error)
+	SwitchPortCatalog(ctx context.Context, switchID string) (map[int]spectrumservice.MetricDetail, error)
+	Hosts(ctx context.Context) ([]spectrumservice.Host, error)
+	HostCatalog(ctx context.Context, hostID string) (map[int]spectrumservice.MetricDetail, error)
+}
+
+var _ API = (*spectrumservice.Client)(nil)
+
+// Group is a set of metrics supported by the same device types
+type Group struct {
+	DeviceTypes []string
+	Metrics     []monitoring.Metric
+}
+
+// Result is the metrics discovered, grouped by resource type as in the metrics configuration file
+type Result struct {
+	StorageSystems           []Group
+	StorageSystemsAndVolumes []Group
+	Switches                 []Group
+	SwitchPorts              []Group
+	Hosts                    []Group
+	Ports                    []Group
+	Nodes                    []Group
+	IOGroups                 []Group
+	ManagedDisks             []Group
+	Volumes                  []Group
+}
+
+// catalog gathers the metrics of a resource type and the device types supporting each metric
+type catalog struct {
+	details     map[int]spectrumservice.MetricDetail
+	deviceTypes map[int]map[string]bool
+}
+
+func newCatalog() *catalog {
+	return &catalog{details: make(map[int]spectrumservice.MetricDetail), deviceTypes: make(map[int]map[string]bool)}
+}
+
+func (c *catalog) add(deviceType string, metrics map[int]spectrumservice.MetricDetail) {
+	for metricID, detail := range metrics {
+		c.details[metricID] = detail
+		if c.deviceTypes[metricID] == nil {
+			c.deviceTypes[metricID] = make(map[string]bool)
+		}
+		c.deviceTypes[metricID][deviceType] = true
+	}
+}
+
+// Discover reads the metric catalogs of one storage system, its volumes, ports, nodes, I/O groups and managed disks,
+// one switch and its ports and one host of each device type
+func Discover(ctx context.Context, logger *zap.Logger, api API) (*Result, error) {
+	storages, switches, switchPorts, hosts := newCatalog(), newCatalog(), newCatalog(), newCatalog()
+	volumes, ports, nodes, managedDisks := newCatalog(), newCatalog(), newCatalog(), newCatalog()
+	ioGroups := newCatalog()
+
+	storageSystems, err := api.StorageSystems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for deviceType, storage := range storageSystemsByType(storageSystems) {
+		logger.Sugar().Infof("Discovering the metrics of %s storage systems on %s.", deviceType, storage.Name)
+
+		metrics, err := api.StorageSystemCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the metrics of storage system %s: %v", storage.Name, err)
+			continue
+		}
+		storages.add(deviceType, metrics)
+
+		metrics, err = api.VolumeCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the volume metrics of storage system %s: %v", storage.Name, err)
+			continue
+		}
+		volumes.add(deviceType, metrics)
+
+		metrics, err = api.PortCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the port metrics of storage system %s: %v", storage.Name, err)
+			continue
+		}
+		ports.add(deviceType, metrics)
+
+		metrics, err = api.NodeCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the node metrics of storage system %s: %v", storage.Name, err)
+			continue
+		}
+		nodes.add(deviceType, metrics)
+
+		metrics, err = api.IOGroupCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the I/O group metrics of storage system %s: %v", storage.Name,
+				err)
+			continue
+		}
+		ioGroups.add(deviceType, metrics)
+
+		metrics, err = api.ManagedDiskCatalog(ctx, storage.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the managed disk metrics of storage system %s: %v", storage.Name,
+				err)
+			continue
+		}
+		managedDisks.add(deviceType, metrics)
+	}
+
+	switchList, err := api.Switches(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for deviceType, s := range switchesByType(switchList) {
+		logger.Sugar().Infof("Discovering the metrics of %s switches on %s.", deviceType, s.Name)
+
+		metrics, err := api.SwitchCatalog(ctx, s.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the metrics of switch %s: %v", s.Name, err)
+			continue
+		}
+		switches.add(deviceType, metrics)
+
+		metrics, err = api.SwitchPortCatalog(ctx, s.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the port metrics of switch %s: %v", s.Name, err)
+			continue
+		}
+		switchPorts.add(deviceType, metrics)
+	}
+
+	hostList, err := api.Hosts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for deviceType, host := range hostsByType(hostList) {
+		logger.Sugar().Infof("Discovering the metrics of %s hosts on %s.", deviceType, host.Name)
+
+		metrics, err := api.HostCatalog(ctx, host.ID)
+		if stop := stopError(ctx, err); stop != nil {
+			return nil, stop
+		}
+		if err != nil {
+			logger.Sugar().Warnf("Error discovering the metrics of host %s: %v", host.Name, err)
+			continue
+		}
+		hosts.add(deviceType, metrics)
+	}
+
+	// the storage system metrics also available on the volumes are written in the storage_systems_and_volumes
+	// section, as in the previous versions, and in the volumes section for the volume collector. That section
+	// is collected from the storage systems, the metrics only available on the volumes are left out of it.
+	storageOnly, storageAndVolumes := newCatalog(), newCatalog()
+	for metricID, detail := range storages.details {
+		split := storageOnly
+		if _, found := volumes.details[metricID]; found {
+			split = storageAndVolumes
+		}
+		split.details[metricID] = detail
+		split.deviceTypes[metricID] = storages.deviceTypes[metricID]
+	}
+
+	names := make(map[string]bool)
+	return &Result{
+		StorageSystems:           storageOnly.groups("storage_", names),
+		StorageSystemsAndVolumes: storageAndVolumes.groups("storage_", names),
+		Switches:                 switches.groups("storage_switch_", names),
+		SwitchPorts:              switchPorts.groups("storage_switch_port_", names),
+		Hosts:                    hosts.groups("storage_host_", names),
+		Ports:                    ports.groups("storage_port_", names),
+		Nodes:                    nodes.groups("storage_node_", names),
+		IOGroups:                 ioGroups.groups("storage_io_group_", names),
+		ManagedDisks:             managedDisks.groups("storage_managed_disk_", names),
+		Volumes:                  volumes.groups("storage_volume_", names),
+	}, nil
+}
+
+// groups returns the metrics grouped by the device types supporting them, the metrics supported by the most
+// device types first. The names already taken are suffixed by the metric ID.
+func (c *catalog) groups(prefix string, names map[string]bool) []Group {
+	metricIDs := make([]int, 0, len(c.details))
+	for metricID := range c.details {
+		metricIDs = append(metricIDs, metricID)
+	}
+	sort.Ints(metricIDs)
+
+	var groups []Group
+	index := make(map[string]int)
+	for _, metricID := range metricIDs {
+		var deviceTypes []string
+		for deviceType := range c.deviceTypes[metricID] {
+			deviceTypes = append(deviceTypes, deviceType)
+		}
+		sort.Strings(deviceTypes)
+
+		key := strings.Join(deviceTypes, "\n")
+		i, found := index[key]
+		if !found {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, Group{DeviceTypes: deviceTypes})
+		}
+
+		detail := c.details[metricID]
+		name := prefix + MetricName(detail.Name, detail.Units)
+		if names[name] {
+			name = fmt.Sprintf("%s_%d", name, metricID)
+		}
+		names[name] = true
+
+		groups[i].Metrics = append(groups[i].Metrics, monitoring.Metric{
+			MetricID:       metricID,
+			PrometheusName: name,
+			PrometheusHelp: MetricHelp(detail),
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].DeviceTypes) > len(groups[j].DeviceTypes)
+	})
+	return groups
+}
+
+// stopError returns the error stopping the discovery, an authentication error or the cancellation of the context,
+// nil when the discovery goes on with the next resource
+func stopError(ctx context.Context, err error) error {
+	switch {
+	case spectrumservice.IsUnauthorized(err):
+		return err
+	case ctx.Err() != nil && err != nil:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// storageSystemsByType returns the first storage system of each device type
+func storageSystemsByType(storageSystems []spectrumservice.StorageSystem) map[string]spectrumservice.StorageSystem {
+	first := make(map[string]spectrumservice.StorageSystem)
+	for _, storage := range storageSystems {
+		deviceType := firstNonEmpty(storage.Type, storage.Model, "unknown")
+		if _, found := first[deviceType]; !found {
+			first[deviceType] = storage
+		}
+	}
+	return first
+}
+
+// switchesByType returns the first switch of each vendor
+func switchesByType(switches []spectrumservice.Switch) map[string]spectrumservice.Switch {
+	first := make(map[string]spectrumservice.Switch)
+	for _, s := range switches {
+		deviceType := firstNonEmpty(s.Vendor, s.Model, "unknown")
+		if _, found := first[deviceType]; !found {
+			first[deviceType] = s
+		}
+	}
+	return first
+}
+
+// hostsByType returns the first host of each OS type
+func hostsByType(hosts []spectrumservice.Host) map[string]spectrumservice.Host {
+	first := make(map[string]spectrumservice.Host)
+	for _, host := range hosts {
+		deviceType := firstNonEmpty(host.OSType, host.Type, "unknown")
+		if _, found := first[deviceType]; !found {
+			first[deviceType] = host
+		}
+	}
+	return first
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
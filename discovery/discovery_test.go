@@ -0,0 +1,250 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+var (
+	readRate = spectrumservice.MetricDetail{Name: "Read I/O Rate - overall", Units: "ops/s",
+		Description: "Average number of read operations per second,\n for a particular component."}
+	writeRate = spectrumservice.MetricDetail{Name: "Write I/O Rate - overall", Units: "ops/s",
+		Description: "Average number of write operations per second."}
+	cacheHit = spectrumservice.MetricDetail{Name: "Overall Data Cache Hit Percentage", Units: "%",
+		Description: "The percentage of all data that was read from the cache."}
+	linkErrors = spectrumservice.MetricDetail{Name: "Invalid Transmission Word Rate", Units: "cnt/s",
+		Description: "The average number of times per second that an invalid transmission word was detected."}
+	portRate = spectrumservice.MetricDetail{Name: "Total Data Rate", Units: "MiB/s",
+		Description: "Average number of mebibytes (2^20 bytes) transferred per second."}
+)
+
+// fakeAPI returns the catalogs of the resources by id
+type fakeAPI struct {
+	storages []spectrumservice.StorageSystem
+	switches []spectrumservice.Switch
+	hosts    []spectrumservice.Host
+	catalogs map[string]map[int]spectrumservice.MetricDetail
+}
+
+func (f *fakeAPI) StorageSystems(ctx context.Context) ([]spectrumservice.StorageSystem, error) {
+	return f.storages, nil
+}
+
+func (f *fakeAPI) Switches(ctx context.Context) ([]spectrumservice.Switch, error) {
+	return f.switches, nil
+}
+
+func (f *fakeAPI) StorageSystemCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id], nil
+}
+
+func (f *fakeAPI) VolumeCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/volumes"], nil
+}
+
+func (f *fakeAPI) PortCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/ports"], nil
+}
+
+func (f *fakeAPI) NodeCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/nodes"], nil
+}
+
+func (f *fakeAPI) IOGroupCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/iogroups"], nil
+}
+
+func (f *fakeAPI) ManagedDiskCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/mdisks"], nil
+}
+
+func (f *fakeAPI) SwitchCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id], nil
+}
+
+func (f *fakeAPI) SwitchPortCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id+"/ports"], nil
+}
+
+func (f *fakeAPI) Hosts(ctx context.Context) ([]spectrumservice.Host, error) {
+	return f.hosts, nil
+}
+
+func (f *fakeAPI) HostCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail, error) {
+	return f.catalogs[id], nil
+}
+
+func TestDiscover(t *testing.T) {
+	api := &fakeAPI{
+		storages: []spectrumservice.StorageSystem{
+			{ID: "1", Name: "SVC1", Type: "SVC"},
+			{ID: "2", Name: "SVC2", Type: "SVC"},
+			{ID: "3", Name: "DS1", Type: "DS8000"},
+		},
+		switches: []spectrumservice.Switch{{ID: "5", Name: "sw1", Vendor: "Brocade"}},
+		hosts: []spectrumservice.Host{
+			{ID: "31", Name: "app01", OSType: "Linux"},
+			{ID: "32", Name: "app02", OSType: "Linux"},
+		},
+		catalogs: map[string]map[int]spectrumservice.MetricDetail{
+			"1":          {803: readRate, 1000: cacheHit, 1029: linkErrors},
+			"1/volumes":  {803: readRate, 806: writeRate, 1000: cacheHit},
+			"3":          {803: readRate},
+			"3/volumes":  {803: readRate},
+			"3/ports":    {1029: linkErrors},
+			"1/nodes":    {1000: cacheHit},
+			"1/iogroups": {1000: cacheHit},
+			"1/mdisks":   {803: readRate},
+			"5":          {860: portRate},
+			"5/ports":    {860: portRate},
+			"31":         {803: readRate},
+		},
+	}
+
+	result, err := Discover(context.Background(), zap.NewNop(), api)
+	if err != nil {
+		t.Fatalf("Discover() returned an error: %v", err)
+	}
+
+	expected := &Result{
+		StorageSystems: []Group{{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+			{MetricID: 1029, PrometheusName: "storage_invalid_transmission_word_rate_per_second",
+				PrometheusHelp: linkErrors.Description},
+		}}},
+		StorageSystemsAndVolumes: []Group{
+			{DeviceTypes: []string{"DS8000", "SVC"}, Metrics: []monitoring.Metric{
+				{MetricID: 803, PrometheusName: "storage_read_io_rate_overall_ops_per_second",
+					PrometheusHelp: "Average number of read operations per second, for a particular component."},
+			}},
+			{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+				{MetricID: 1000, PrometheusName: "storage_overall_data_cache_hit_percent",
+					PrometheusHelp: cacheHit.Description},
+			}},
+		},
+		Switches: []Group{{DeviceTypes: []string{"Brocade"}, Metrics: []monitoring.Metric{
+			{MetricID: 860, PrometheusName: "storage_switch_total_data_rate_mebibytes_per_second",
+				PrometheusHelp: portRate.Description},
+		}}},
+		SwitchPorts: []Group{{DeviceTypes: []string{"Brocade"}, Metrics: []monitoring.Metric{
+			{MetricID: 860, PrometheusName: "storage_switch_port_total_data_rate_mebibytes_per_second",
+				PrometheusHelp: portRate.Description},
+		}}},
+		Hosts: []Group{{DeviceTypes: []string{"Linux"}, Metrics: []monitoring.Metric{
+			{MetricID: 803, PrometheusName: "storage_host_read_io_rate_overall_ops_per_second",
+				PrometheusHelp: "Average number of read operations per second, for a particular component."},
+		}}},
+		Ports: []Group{{DeviceTypes: []string{"DS8000"}, Metrics: []monitoring.Metric{
+			{MetricID: 1029, PrometheusName: "storage_port_invalid_transmission_word_rate_per_second",
+				PrometheusHelp: linkErrors.Description},
+		}}},
+		Nodes: []Group{{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+			{MetricID: 1000, PrometheusName: "storage_node_overall_data_cache_hit_percent",
+				PrometheusHelp: cacheHit.Description},
+		}}},
+		IOGroups: []Group{{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+			{MetricID: 1000, PrometheusName: "storage_io_group_overall_data_cache_hit_percent",
+				PrometheusHelp: cacheHit.Description},
+		}}},
+		ManagedDisks: []Group{{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+			{MetricID: 803, PrometheusName: "storage_managed_disk_read_io_rate_overall_ops_per_second",
+				PrometheusHelp: "Average number of read operations per second, for a particular component."},
+		}}},
+		Volumes: []Group{
+			{DeviceTypes: []string{"DS8000", "SVC"}, Metrics: []monitoring.Metric{
+				{MetricID: 803, PrometheusName: "storage_volume_read_io_rate_overall_ops_per_second",
+					PrometheusHelp: "Average number of read operations per second, for a particular component."},
+			}},
+			{DeviceTypes: []string{"SVC"}, Metrics: []monitoring.Metric{
+				{MetricID: 806, PrometheusName: "storage_volume_write_io_rate_overall_ops_per_second",
+					PrometheusHelp: writeRate.Description},
+				{MetricID: 1000, PrometheusName: "storage_volume_overall_data_cache_hit_percent",
+					PrometheusHelp: cacheHit.Description},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Discover() = %+v, expected %+v", result, expected)
+	}
+
+	// the generated file is a valid metrics configuration
+	pools := []monitoring.Property{{PropertyName: "Capacity", PrometheusName: "storage_usable_capacity_GiB",
+		PrometheusHelp: "Usable Capacity"}}
+	groups := []monitoring.PropertyGroup{{PrometheusName: "storage_pool_tier_capacity_bytes",
+		PrometheusHelp: "Capacity of the pool by tier", Label: "tier", Unit: "GiB",
+		Properties: []monitoring.GroupedProperty{{PropertyName: "Tier 0 Flash Capacity", LabelValue: "tier0_flash"},
+			{PropertyName: "Nearline HDD Capacity", LabelValue: "nearline_hdd"}}}}
+	var existing monitoring.MetricsConfig
+	existing.Metrics.Pools.Properties = pools
+	existing.Metrics.Pools.PropertyGroups = groups
+	var buffer bytes.Buffer
+	if err := result.Write(&buffer, existing); err != nil {
+		t.Fatalf("Write() returned an error: %v", err)
+	}
+
+	var config monitoring.MetricsConfig
+	if err := yaml.UnmarshalStrict(buffer.Bytes(), &config); err != nil {
+		t.Fatalf("invalid metrics configuration: %v\n%s", err, buffer.String())
+	}
+	if len(config.PerformanceMetrics()) != 13 || !reflect.DeepEqual(config.Metrics.Pools.Properties, pools) ||
+		!reflect.DeepEqual(config.Metrics.Pools.PropertyGroups, groups) {
+		t.Errorf("unexpected metrics configuration:\n%s", buffer.String())
+	}
+	if config.Metrics.StorageSystemsAndVolumes[0] != expected.StorageSystemsAndVolumes[0].Metrics[0] {
+		t.Errorf("metric written as %+v", config.Metrics.StorageSystemsAndVolumes[0])
+	}
+}
+
+// cancellingAPI cancels the discovery once the catalog of a storage system is returned
+type cancellingAPI struct {
+	*fakeAPI
+	cancel context.CancelFunc
+}
+
+func (c *cancellingAPI) StorageSystemCatalog(ctx context.Context, id string) (map[int]spectrumservice.MetricDetail,
+	error) {
+	c.cancel()
+	return c.fakeAPI.StorageSystemCatalog(ctx, id)
+}
+
+func TestDiscoverCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	api := &cancellingAPI{fakeAPI: &fakeAPI{
+		storages: []spectrumservice.StorageSystem{{ID: "1", Name: "v7000", Type: "SVC"}},
+		catalogs: map[string]map[int]spectrumservice.MetricDetail{"1": {803: readRate}},
+	}, cancel: cancel}
+
+	result, err := Discover(ctx, zap.NewNop(), api)
+	if err != context.Canceled {
+		t.Errorf("Discover() = %v, %v, expected the context cancellation", result, err)
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	tests := []struct {
+		name, units, expected string
+	}{
+		{"Read I/O Rate - overall", "ops/s", "read_io_rate_overall_ops_per_second"},
+		{"Read Response Time", "ms/op", "read_response_time_milliseconds"},
+		{"Overall Data Cache Hit Percentage", "%", "overall_data_cache_hit_percent"},
+		{"Read Transfer Size", "KiB/op", "read_transfer_size_kibibytes"},
+		{"Port Send Bandwidth Percentage", "%", "port_send_bandwidth_percent"},
+		{"CPU Utilization", "", "cpu_utilization"},
+		{"Frames Rate", "frames/s", "frames_rate_frames_per_second"},
+	}
+
+	for _, test := range tests {
+		if name := MetricName(test.name, test.units); name != test.expected {
+			t.Errorf("MetricName(%q, %q) = %s, expected %s", test.name, test.units, name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package discovery
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
+)
+
+// unitSuffixes translates the IBM Spectrum units into Prometheus name suffixes, the values are not converted
+var unitSuffixes = map[string]string{
+	"%":      "percent",
+	"ms/op":  "milliseconds",
+	"ms":     "milliseconds",
+	"s":      "seconds",
+	"ops/s":  "ops_per_second",
+	"cnt/s":  "per_second",
+	"KiB/op": "kibibytes",
+	"KiB":    "kibibytes",
+	"MiB/s":  "mebibytes_per_second",
+	"MiB":    "mebibytes",
+	"GiB":    "gibibytes",
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// MetricName returns a Prometheus metric name, without namespace, from the name and units of an IBM Spectrum metric.
+// "Read I/O Rate - overall" in ops/s becomes read_io_rate_overall_ops_per_second.
+func MetricName(name, units string) string {
+	name = strings.ReplaceAll(strings.ToLower(name), "i/o", "io")
+	name = strings.Trim(invalidNameChars.ReplaceAllString(name, "_"), "_")
+
+	suffix, found := unitSuffixes[units]
+	if !found {
+		suffix = strings.ToLower(units)
+		if strings.HasSuffix(suffix, "/s") {
+			suffix = strings.TrimSuffix(suffix, "/s") + "/second"
+		}
+		suffix = strings.ReplaceAll(suffix, "/", "_per_")
+		suffix = strings.Trim(invalidNameChars.ReplaceAllString(suffix, "_"), "_")
+	}
+
+	switch {
+	case suffix == "" || strings.HasSuffix(name, "_"+suffix) || name == suffix:
+		return name
+	case suffix == "percent" && strings.HasSuffix(name, "_percentage"):
+		return strings.TrimSuffix(name, "age")
+	case name == "":
+		return suffix
+	}
+	return name + "_" + suffix
+}
+
+// MetricHelp returns the help text of a metric, its IBM Spectrum description on a single line
+func MetricHelp(detail spectrumservice.MetricDetail) string {
+	help := strings.Join(strings.Fields(detail.Description), " ")
+	if help == "" {
+		help = detail.Name
+	}
+	return help
+}
@@ -0,0 +1,104 @@
+package discovery
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+)
+
+// the configuration is written with a template, yaml.v2 does not write comments
+var configTemplate = template.Must(template.New("metrics_conf.yaml").Funcs(template.FuncMap{
+	"join":    strings.Join,
+	"quote":   strconv.Quote,
+	"section": newSection,
+}).Parse(`# Generated by ibm-spectrum-exporter discover, remove the metrics which are not needed.
+metrics:
+
+  storage_systems:
+{{- template "section" section "metrics" "  " .Result.StorageSystems}}
+{{- template "properties" .Config.Metrics.StorageSystems.PropertiesConfig}}
+{{template "section" section "storage_systems_and_volumes" "" .Result.StorageSystemsAndVolumes}}
+{{template "section" section "switches" "" .Result.Switches}}
+{{template "section" section "switch_ports" "" .Result.SwitchPorts}}
+{{template "section" section "hosts" "" .Result.Hosts}}
+{{template "section" section "ports" "" .Result.Ports}}
+{{template "section" section "nodes" "" .Result.Nodes}}
+{{template "section" section "io_groups" "" .Result.IOGroups}}
+
+  managed_disks:
+{{- template "section" section "metrics" "  " .Result.ManagedDisks}}
+{{- template "properties" .Config.Metrics.ManagedDisks.PropertiesConfig}}
+
+  pools:
+{{- template "properties" .Config.Metrics.Pools}}
+
+  volumes:
+{{- template "section" section "metrics" "  " .Result.Volumes}}
+{{- template "properties" .Config.Metrics.Volumes.PropertiesConfig}}
+
+  disks:
+{{- template "properties" .Config.Metrics.Disks}}
+{{- define "properties"}}
+    properties:
+{{- if not .Properties}} []{{end}}
+{{- range .Properties}}
+      - property_name: {{quote .PropertyName}}
+        prometheus_name: {{.PrometheusName}}
+        prometheus_help: {{quote .PrometheusHelp}}
+{{- if .Unit}}
+        unit: {{.Unit}}
+{{- end}}
+{{end}}
+{{- if .PropertyGroups}}
+    property_groups:
+{{- range .PropertyGroups}}
+      - prometheus_name: {{.PrometheusName}}
+        prometheus_help: {{quote .PrometheusHelp}}
+        label: {{.Label}}
+{{- if .Unit}}
+        unit: {{.Unit}}
+{{- end}}
+        properties:
+{{- range .Properties}}
+          - property_name: {{quote .PropertyName}}
+            label_value: {{quote .LabelValue}}
+{{- end}}
+{{end}}
+{{- end}}
+{{- end}}
+{{- define "section"}}
+  {{.Indent}}{{.Name}}:
+{{- if not .Groups}} []{{end}}
+{{- range .Groups}}
+{{$.Indent}}    # Supported by: {{join .DeviceTypes ", "}}
+{{- range .Metrics}}
+{{$.Indent}}    - ibm_spectrum_metric_id: {{.MetricID}}
+{{$.Indent}}      prometheus_name: {{.PrometheusName}}
+{{$.Indent}}      prometheus_help: {{quote .PrometheusHelp}}
+{{end}}
+{{- end}}
+{{- end}}
+`))
+
+// section is a list of metrics, indented by Indent in addition to the sections indentation
+type section struct {
+	Name   string
+	Indent string
+	Groups []Group
+}
+
+func newSection(name, indent string, groups []Group) section {
+	return section{Name: name, Indent: indent, Groups: groups}
+}
+
+// Write writes the metrics configuration file of the discovered metrics. The properties are not
+// discovered, the properties and property groups of the given configuration are written.
+func (r *Result) Write(w io.Writer, config monitoring.MetricsConfig) error {
+	return configTemplate.Execute(w, struct {
+		Result *Result
+		Config monitoring.MetricsConfig
+	}{r, config})
+}
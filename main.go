@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -13,6 +23,7 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 
 	"github.com/topine/ibm-spectrum-exporter/collector"
+	"github.com/topine/ibm-spectrum-exporter/discovery"
 	"github.com/topine/ibm-spectrum-exporter/monitoring"
 	"github.com/topine/ibm-spectrum-exporter/spectrumservice"
 )
@@ -36,20 +47,45 @@ func main() {
 		addr               = kingpin.Flag("listen-address", "Address on which to expose metrics and web interface.").Default(":9741").String()
 		metricsPath        = kingpin.Flag("telemetry-path", "Path under which to expose metrics.").Default("/metrics").String()
 		metricConfigPath   = kingpin.Flag("metric-config-path", "Metric configuration file absolute path").Default("metrics_conf.yaml").String()
-		baseURL            = kingpin.Flag("base-url", "IBM Spectrum base url").Short('t').Required().String()
+		serversConfigPath  = kingpin.Flag("servers-config-path", "IBM Spectrum servers configuration file, replacing the base url, user and password flags").String()
+		baseURL            = kingpin.Flag("base-url", "IBM Spectrum base url").Short('t').String()
 		cacheMetrics       = kingpin.Flag("cache-metrics", "Cache metrics to avoid multiple calls").Default("true").Bool()
 		collectionInterval = kingpin.Flag("collection-interval", "Metrics Collection interval").Default("@every 5m").String()
-		user               = kingpin.Flag("user", "IBM Spectrum username").Short('u').Required().String()
-		password           = kingpin.Flag("password", "IBM Spectrum username").Short('p').Required().String()
+		user               = kingpin.Flag("user", "IBM Spectrum username").Short('u').String()
+		password           = kingpin.Flag("password", "IBM Spectrum username").Short('p').String()
+		tlsCAFile          = kingpin.Flag("tls.ca-file", "PEM bundle of the CAs trusted to sign the IBM Spectrum certificate").String()
+		tlsFingerprint     = kingpin.Flag("tls.server-fingerprint", "SHA-256 fingerprint of the pinned IBM Spectrum certificate").String()
+		tlsCertFile        = kingpin.Flag("tls.cert-file", "Client certificate file for mutual TLS").String()
+		tlsKeyFile         = kingpin.Flag("tls.key-file", "Client key file for mutual TLS").String()
+		tlsMinVersion      = kingpin.Flag("tls.min-version", "Minimum TLS version (1.0, 1.1, 1.2 or 1.3)").Default("1.2").String()
+		tlsInsecure        = kingpin.Flag("tls.insecure-skip-verify", "Accept any IBM Spectrum certificate (insecure)").Default("false").Bool()
+		retryAttempts      = kingpin.Flag("retry.max-attempts", "Maximum number of attempts of an IBM Spectrum request").Default("3").Int()
+		retryBackoff       = kingpin.Flag("retry.initial-backoff", "Wait before the first retry, doubled at each retry").Default("1s").Duration()
+		retryMaxBackoff    = kingpin.Flag("retry.max-backoff", "Maximum wait between two attempts").Default("30s").Duration()
+		concurrency        = kingpin.Flag("collection.concurrency", "Number of storage systems collected in parallel").Default("4").Int()
+		maxInFlight        = kingpin.Flag("collection.max-in-flight-requests", "Maximum number of concurrent requests to each IBM Spectrum server, 0 for no limit").Default("8").Int()
+		rateLimit          = kingpin.Flag("rate-limit.requests-per-second", "Maximum rate of the requests to each IBM Spectrum server, 0 for no limit").Default("0").Float64()
+		rateLimitBurst     = kingpin.Flag("rate-limit.burst", "Number of requests sent at once before being rate limited").Default("1").Int()
+		perfRateLimit      = kingpin.Flag("rate-limit.performance-requests-per-second", "Maximum rate of the requests to the performance endpoints, 0 for no limit").Default("0").Float64()
+		perfRateLimitBurst = kingpin.Flag("rate-limit.performance-burst", "Number of performance requests sent at once before being rate limited").Default("1").Int()
+		invRateLimit       = kingpin.Flag("rate-limit.inventory-requests-per-second", "Maximum rate of the requests to the inventory endpoints, 0 for no limit").Default("0").Float64()
+		invRateLimitBurst  = kingpin.Flag("rate-limit.inventory-burst", "Number of inventory requests sent at once before being rate limited").Default("1").Int()
+		timeoutOffset      = kingpin.Flag("scrape-timeout-offset", "Offset to subtract from the Prometheus scrape timeout").Default("500ms").Duration()
 
-		config         monitoring.MetricsConfig
-		spectrumClient *spectrumservice.Client
-		localCache     *cache.Cache
+		serveCmd       = kingpin.Command("serve", "Expose the IBM Spectrum metrics to Prometheus.").Default()
+		discoverCmd    = kingpin.Command("discover", "Discover the IBM Spectrum metrics and generate a metrics configuration file.")
+		discoverOutput = discoverCmd.Flag("output", "File to write the metrics configuration to, - for the standard output").Short('o').Default("-").String()
+		discoverServer = discoverCmd.Flag("server", "Name of the server to discover in the servers configuration file, the first one by default").String()
+
+		config     monitoring.MetricsConfig
+		servers    monitoring.ServersConfig
+		targets    []*target
+		localCache *cache.Cache
 	)
 
 	//kingpin.Version(version.Print("ibm-spectrum-exporter"))
 	kingpin.HelpFlag.Short('h')
-	kingpin.Parse()
+	command := kingpin.Parse()
 
 	logger, err := zap.NewDevelopment()
 	//logger, err := zap.NewProduction()
@@ -57,48 +93,167 @@ func main() {
 		panic(err)
 	}
 
-	if *baseURL == "" {
-		logger.Sugar().Panic("IBM Spectrum base url missing")
-	}
-
+	// the discovery only reuses the properties of an existing metrics configuration
 	err = config.GetConf(*metricConfigPath)
-	if err != nil {
+	if err != nil && command == serveCmd.FullCommand() {
 		logger.Sugar().Fatal("Error parsing the metrics configuration file: %v", err)
 	}
-	buildInfos()
+
+	if *serversConfigPath != "" {
+		err = servers.GetConf(*serversConfigPath)
+		if err != nil {
+			logger.Sugar().Fatalf("Error parsing the servers configuration file: %v", err)
+		}
+	} else {
+		if *baseURL == "" || *user == "" || *password == "" {
+			logger.Sugar().Fatal("IBM Spectrum base url, user and password are required without servers configuration file")
+		}
+		servers.Servers = []monitoring.ServerConfig{{Name: serverName(*baseURL), BaseURL: *baseURL,
+			User: *user, Password: *password}}
+	}
+
+	// the root context is cancelled on shutdown, stopping the in-flight collections
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		logger.Sugar().Info("Shutting down the exporter.")
+		cancel()
+	}()
 
 	//starting cache
 	localCache = cache.New(cache.NoExpiration, cache.NoExpiration)
 
+	// newClient creates the client of an IBM Spectrum server, configured by the flags
+	newClient := func(server monitoring.ServerConfig, serverLogger *zap.Logger) (*spectrumservice.Client, error) {
+		// the server TLS settings override the flags
+		tlsOptions := spectrumservice.TLSOptions{
+			CAFile:             firstNonEmpty(server.TLS.CAFile, *tlsCAFile),
+			ServerFingerprint:  firstNonEmpty(server.TLS.ServerFingerprint, *tlsFingerprint),
+			CertFile:           firstNonEmpty(server.TLS.CertFile, *tlsCertFile),
+			KeyFile:            firstNonEmpty(server.TLS.KeyFile, *tlsKeyFile),
+			MinVersion:         firstNonEmpty(server.TLS.MinVersion, *tlsMinVersion),
+			InsecureSkipVerify: server.TLS.InsecureSkipVerify || *tlsInsecure,
+		}
+
+		spectrumClient, err := spectrumservice.NewClient(serverLogger.Sugar(), config, localCache, *cacheMetrics,
+			server.User, server.Password, server.BaseURL, tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+		spectrumClient.Name = server.Name
+		spectrumClient.Retry = spectrumservice.RetryPolicy{
+			MaxAttempts:    *retryAttempts,
+			InitialBackoff: *retryBackoff,
+			MaxBackoff:     *retryMaxBackoff,
+		}
+		spectrumClient.Concurrency = *concurrency
+		spectrumClient.SetMaxInFlightRequests(*maxInFlight)
+		spectrumClient.SetRateLimit(spectrumservice.AllEndpoints,
+			spectrumservice.RateLimit{RequestsPerSecond: *rateLimit, Burst: *rateLimitBurst})
+		spectrumClient.SetRateLimit(spectrumservice.PerformanceEndpoints,
+			spectrumservice.RateLimit{RequestsPerSecond: *perfRateLimit, Burst: *perfRateLimitBurst})
+		spectrumClient.SetRateLimit(spectrumservice.InventoryEndpoints,
+			spectrumservice.RateLimit{RequestsPerSecond: *invRateLimit, Burst: *invRateLimitBurst})
+		return spectrumClient, nil
+	}
+
+	if command == discoverCmd.FullCommand() {
+		server, err := discoverTarget(servers, *discoverServer)
+		if err != nil {
+			logger.Sugar().Fatal(err)
+		}
+		serverLogger := logger.With(zap.String("spectrum_server", server.Name))
+		spectrumClient, err := newClient(server, serverLogger)
+		if err != nil {
+			logger.Sugar().Fatalf("Error creating the IBM Spectrum client for %s: %v", server.Name, err)
+		}
+		err = discoverMetrics(ctx, serverLogger, spectrumClient, config, *discoverOutput)
+		if err := spectrumClient.Logout(context.Background()); err != nil {
+			logger.Sugar().Errorf("Error closing the IBM Spectrum session of %s %v", server.Name, err)
+		}
+		if err != nil {
+			logger.Sugar().Fatalf("Error discovering the metrics of %s: %v", server.Name, err)
+		}
+		return
+	}
+	buildInfos()
+
 	//set all the metrics
-	spectrumClient = spectrumservice.NewClient(logger.Sugar(), config, localCache, *cacheMetrics,
-		*user, *password, *baseURL)
+	for _, server := range servers.Servers {
+		serverLogger := logger.With(zap.String("spectrum_server", server.Name))
+
+		spectrumClient, err := newClient(server, serverLogger)
+		if err != nil {
+			logger.Sugar().Fatalf("Error creating the IBM Spectrum client for %s: %v", server.Name, err)
+		}
+
+		state, filter := collector.ServerSettings(server)
+		spectrumCollector, err := collector.NewIbmSpectrumCollector(config, serverLogger, spectrumClient, state, filter)
+		if err != nil {
+			logger.Sugar().Fatal("Error creating collector: %v", err)
+		}
+
+		targets = append(targets, &target{name: server.Name, client: spectrumClient, collector: spectrumCollector,
+			state: state, filter: filter})
+	}
 
 	//Create a cron that will start a go routine to update the metrics
 	c := cron.New()
 
 	if *cacheMetrics {
-		collectMetrics(logger, spectrumClient)
-		//Create a cron that will start a go routine to update the metrics
-		err = c.AddFunc(*collectionInterval, func() {
-			collectMetrics(logger, spectrumClient)
-		})
-		if err != nil {
-			logger.Sugar().Fatalf("Cannot schedule collection %v", err)
-		} else {
-			logger.Sugar().Infof("Scheduler started with success with interval %s \n", *collectionInterval)
+		var wg sync.WaitGroup
+		for _, t := range targets {
+			wg.Add(1)
+			go func(t *target) {
+				defer wg.Done()
+				collectMetrics(ctx, logger, t)
+			}(t)
+
+			t := t
+			//Create a cron that will start a go routine to update the metrics
+			err = c.AddFunc(*collectionInterval, func() {
+				collectMetrics(ctx, logger, t)
+			})
+			if err != nil {
+				logger.Sugar().Fatalf("Cannot schedule collection %v", err)
+			}
 		}
+		wg.Wait()
+		logger.Sugar().Infof("Scheduler started with success with interval %s \n", *collectionInterval)
 	}
 
 	c.Start()
 
-	spectrumCollector, err := collector.NewIbmSpectrumCollector(config, logger, *spectrumClient)
-	if err != nil {
-		logger.Sugar().Fatal("Error creating collector: %v", err)
+	// the metrics of the collectors are checked once at startup, rather than failing every scrape
+	if err := registerTargets(ctx, prometheus.NewRegistry(), targets); err != nil {
+		logger.Sugar().Fatal(err)
 	}
 
-	prometheus.MustRegister(spectrumCollector)
-	http.Handle(*metricsPath, promhttp.Handler())
+	prometheus.MustRegister(spectrumservice.Metrics()...)
+	http.HandleFunc(*metricsPath, func(w http.ResponseWriter, r *http.Request) {
+		scrapeCtx := r.Context()
+		if timeout, err := scrapeTimeout(r, *timeoutOffset); err != nil {
+			logger.Sugar().Warnf("Ignoring the scrape timeout header: %v", err)
+		} else if timeout > 0 {
+			var cancelScrape context.CancelFunc
+			scrapeCtx, cancelScrape = context.WithTimeout(scrapeCtx, timeout)
+			defer cancelScrape()
+		}
+
+		// the collectors are registered for each scrape to collect within the scrape deadline
+		registry := prometheus.NewRegistry()
+		if err := registerTargets(scrapeCtx, registry, targets); err != nil {
+			logger.Sugar().Error(err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, registry},
+			promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		_, err := w.Write([]byte(`<html>
@@ -114,18 +269,136 @@ func main() {
 		}
 	})
 
+	server := &http.Server{
+		Addr: *addr,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Sugar().Fatal(err)
+		}
+	}()
+
 	logger.Sugar().Infof("Exporter started with Success.")
-	logger.Sugar().Fatal(http.ListenAndServe(*addr, nil))
+
+	<-ctx.Done()
+	c.Stop()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Sugar().Errorf("Error shutting down the http server %v", err)
+	}
+
+	// close the IBM Spectrum sessions instead of leaving them open on the servers
+	for _, t := range targets {
+		if err := t.client.Logout(shutdownCtx); err != nil {
+			logger.Sugar().Errorf("Error closing the IBM Spectrum session of %s %v", t.name, err)
+		}
+	}
 }
 
-func collectMetrics(logger *zap.Logger, spectrumClient *spectrumservice.Client) {
-	logger.Sugar().Info("Starting to collect the metrics.")
+// target is an IBM Spectrum server monitored by the exporter
+type target struct {
+	name      string
+	client    *spectrumservice.Client
+	collector *collector.IbmSpectrumCollector
+	state     map[string]*bool
+	filter    map[string]*string
+}
 
-	err := spectrumClient.CollectAndCacheMetrics(collector.Filter, collector.State)
-	if err != nil {
+// registerTargets registers the collectors of the servers, each server metrics being labeled with its name
+func registerTargets(ctx context.Context, registry prometheus.Registerer, targets []*target) error {
+	for _, t := range targets {
+		if err := prometheus.WrapRegistererWith(prometheus.Labels{"spectrum_server": t.name}, registry).
+			Register(t.collector.WithContext(ctx)); err != nil {
+			return fmt.Errorf("error registering the collector of %s: %v", t.name, err)
+		}
+	}
+	return nil
+}
+
+func collectMetrics(ctx context.Context, logger *zap.Logger, t *target) {
+	logger.Sugar().Infof("Starting to collect the metrics of %s.", t.name)
+
+	err := t.client.CollectAndCacheMetrics(ctx, t.filter, t.state)
+	if spectrumservice.IsUnauthorized(err) {
+		logger.Sugar().Errorf("IBM Spectrum denied the access, check the user and its permissions %v", err)
+	} else if err != nil {
 		logger.Sugar().Errorf("error Collecting metrics for cache %v", err)
 	}
-	logger.Sugar().Info("Finished collecting metrics")
+	logger.Sugar().Infof("Finished collecting metrics of %s", t.name)
+}
+
+// discoverTarget returns the server to discover, given by its name or the first one
+func discoverTarget(servers monitoring.ServersConfig, name string) (monitoring.ServerConfig, error) {
+	for _, server := range servers.Servers {
+		if name == "" || server.Name == name {
+			return server, nil
+		}
+	}
+	return monitoring.ServerConfig{}, fmt.Errorf("server %s not found in the servers configuration file", name)
+}
+
+// discoverMetrics writes the metrics configuration of the metrics available on the server
+func discoverMetrics(ctx context.Context, logger *zap.Logger, client *spectrumservice.Client,
+	config monitoring.MetricsConfig, output string) error {
+	result, err := discovery.Discover(ctx, logger, client)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := result.Write(&buffer, config); err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err = os.Stdout.Write(buffer.Bytes())
+		return err
+	}
+	logger.Sugar().Infof("Writing the metrics configuration to %s.", output)
+	return ioutil.WriteFile(output, buffer.Bytes(), 0644)
+}
+
+// serverName returns the name of the server given by its base url, its host name
+func serverName(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Hostname() == "" {
+		return baseURL
+	}
+	return u.Hostname()
+}
+
+// firstNonEmpty returns the first value which is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// scrapeTimeout returns the scrape timeout sent by Prometheus minus the offset, 0 when there is none
+func scrapeTimeout(r *http.Request, offset time.Duration) (time.Duration, error) {
+	header := r.Header.Get("X-Prometheus-Scrape-Timeout-Seconds")
+	if header == "" {
+		return 0, nil
+	}
+
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	timeout := time.Duration(seconds*float64(time.Second)) - offset
+	if timeout <= 0 {
+		return 0, fmt.Errorf("scrape timeout %s is shorter than the offset %s", header, offset)
+	}
+	return timeout, nil
 }
 
 // buildInfos returns builds information
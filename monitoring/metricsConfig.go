@@ -1,39 +1,131 @@
 package monitoring
 
 import (
+	"fmt"
 	"io/ioutil"
 
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v2"
+
+	"github.com/topine/ibm-spectrum-exporter/quantity"
 )
 
 // MetricsConfig : Struct to represent the config file
 type MetricsConfig struct {
-	Metrics struct {
-		StorageSystems []struct {
-			MetricID       int    `yaml:"ibm_spectrum_metric_id"`
-			PrometheusName string `yaml:"prometheus_name"`
-			PrometheusHelp string `yaml:"prometheus_help"`
-		} `yaml:"storage_systems"`
-		StorageSystemsAndVolumes []struct {
-			MetricID       int    `yaml:"ibm_spectrum_metric_id"`
-			PrometheusName string `yaml:"prometheus_name"`
-			PrometheusHelp string `yaml:"prometheus_help"`
-		} `yaml:"storage_systems_and_volumes"`
-		Switches []struct {
-			MetricID       int    `yaml:"ibm_spectrum_metric_id"`
-			PrometheusName string `yaml:"prometheus_name"`
-			PrometheusHelp string `yaml:"prometheus_help"`
-		} `yaml:"switches"`
-		Pools struct {
-			Properties []struct {
-				PropertyName   string `yaml:"property_name"`
-				PrometheusName string `yaml:"prometheus_name"`
-				PrometheusHelp string `yaml:"prometheus_help"`
-			} `yaml:"properties"`
-		} `yaml:"pools"`
+	// DecimalSeparator is the decimal separator of the property values, "." by default or ","
+	DecimalSeparator string `yaml:"decimal_separator"`
+	Metrics          struct {
+		StorageSystems           StorageSystemsConfig `yaml:"storage_systems"`
+		StorageSystemsAndVolumes []Metric             `yaml:"storage_systems_and_volumes"`
+		Switches                 []Metric             `yaml:"switches"`
+		SwitchPorts              []Metric             `yaml:"switch_ports"`
+		Hosts                    []Metric             `yaml:"hosts"`
+		Ports                    []Metric             `yaml:"ports"`
+		Nodes                    []Metric             `yaml:"nodes"`
+		IOGroups                 []Metric             `yaml:"io_groups"`
+		ManagedDisks             ResourceConfig       `yaml:"managed_disks"`
+		Pools                    PropertiesConfig     `yaml:"pools"`
+		Volumes                  ResourceConfig       `yaml:"volumes"`
+		Disks                    PropertiesConfig     `yaml:"disks"`
 	} `yaml:"metrics"`
 }
 
+// StorageSystemsConfig : performance metrics and properties of the storage systems. The section is either
+// the list of metrics, or a mapping with the metrics and the properties.
+type StorageSystemsConfig struct {
+	Metrics          []Metric `yaml:"metrics"`
+	PropertiesConfig `yaml:",inline"`
+}
+
+// UnmarshalYAML accepts the list of metrics of the previous versions
+func (s *StorageSystemsConfig) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var node interface{}
+	if err := unmarshal(&node); err != nil {
+		return err
+	}
+	if _, isList := node.([]interface{}); isList {
+		return unmarshal(&s.Metrics)
+	}
+
+	type plain StorageSystemsConfig
+	return unmarshal((*plain)(s))
+}
+
+// ResourceConfig : performance metrics and properties of a resource type
+type ResourceConfig struct {
+	Metrics          []Metric `yaml:"metrics"`
+	PropertiesConfig `yaml:",inline"`
+}
+
+// PropertiesConfig : properties of a resource type, exported as a metric each or grouped by property groups
+type PropertiesConfig struct {
+	Properties     []Property      `yaml:"properties"`
+	PropertyGroups []PropertyGroup `yaml:"property_groups"`
+}
+
+// Metric : translation of an IBM Spectrum performance metric into a prometheus metric
+type Metric struct {
+	MetricID       int    `yaml:"ibm_spectrum_metric_id"`
+	PrometheusName string `yaml:"prometheus_name"`
+	// PrometheusHelp is optional, a help giving only the IBM Spectrum metric ID is used when omitted
+	PrometheusHelp string `yaml:"prometheus_help"`
+}
+
+// Property : translation of an IBM Spectrum resource property into a prometheus metric
+type Property struct {
+	PropertyName   string `yaml:"property_name"`
+	PrometheusName string `yaml:"prometheus_name"`
+	PrometheusHelp string `yaml:"prometheus_help"`
+	// Unit is the unit of the property values given without unit, e.g. GiB, converted to the base unit
+	Unit string `yaml:"unit"`
+}
+
+// PropertyGroup : translation of several IBM Spectrum resource properties into a single prometheus metric,
+// the properties being told apart by the value of Label, e.g. the capacity of each tier of a pool
+type PropertyGroup struct {
+	PrometheusName string            `yaml:"prometheus_name"`
+	PrometheusHelp string            `yaml:"prometheus_help"`
+	Label          string            `yaml:"label"`
+	Unit           string            `yaml:"unit"`
+	Properties     []GroupedProperty `yaml:"properties"`
+}
+
+// GroupedProperty : property of a property group and the value of the group label for this property
+type GroupedProperty struct {
+	PropertyName string `yaml:"property_name"`
+	LabelValue   string `yaml:"label_value"`
+}
+
+// validate checks the units of the properties and the labels of the property groups. The labels of the resources
+// are checked by the collectors.
+func (p PropertiesConfig) validate() error {
+	for _, property := range p.Properties {
+		if !quantity.ValidUnit(property.Unit) {
+			return fmt.Errorf("unknown unit %s of the property %s", property.Unit, property.PropertyName)
+		}
+	}
+	for _, group := range p.PropertyGroups {
+		if !quantity.ValidUnit(group.Unit) {
+			return fmt.Errorf("unknown unit %s of the property group %s", group.Unit, group.PrometheusName)
+		}
+		if group.Label == "" {
+			return fmt.Errorf("missing label of the property group %s", group.PrometheusName)
+		}
+		if !model.LabelName(group.Label).IsValid() {
+			return fmt.Errorf("invalid label %s of the property group %s", group.Label, group.PrometheusName)
+		}
+		labelValues := make(map[string]bool)
+		for _, property := range group.Properties {
+			if labelValues[property.LabelValue] {
+				return fmt.Errorf("duplicate label value %s of the property group %s", property.LabelValue,
+					group.PrometheusName)
+			}
+			labelValues[property.LabelValue] = true
+		}
+	}
+	return nil
+}
+
 // GetConf file from the given path
 func (c *MetricsConfig) GetConf(filePath string) error {
 	yamlFile, err := ioutil.ReadFile(filePath)
@@ -42,5 +134,42 @@ func (c *MetricsConfig) GetConf(filePath string) error {
 	}
 
 	err = yaml.Unmarshal(yamlFile, c)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if !c.Locale().Valid() {
+		return fmt.Errorf("invalid decimal separator %q, expected \".\" or \",\"", c.DecimalSeparator)
+	}
+	for _, properties := range []PropertiesConfig{c.Metrics.StorageSystems.PropertiesConfig, c.Metrics.Pools,
+		c.Metrics.Volumes.PropertiesConfig, c.Metrics.ManagedDisks.PropertiesConfig, c.Metrics.Disks} {
+		if err := properties.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locale returns the formatting of the numbers in the property values
+func (c *MetricsConfig) Locale() quantity.Locale {
+	if c.DecimalSeparator == "" {
+		return quantity.DecimalPoint
+	}
+	return quantity.Locale(c.DecimalSeparator)
+}
+
+// PerformanceMetrics returns all the performance metrics of the config file
+func (c *MetricsConfig) PerformanceMetrics() []Metric {
+	var metrics []Metric
+	metrics = append(metrics, c.Metrics.StorageSystems.Metrics...)
+	metrics = append(metrics, c.Metrics.StorageSystemsAndVolumes...)
+	metrics = append(metrics, c.Metrics.Switches...)
+	metrics = append(metrics, c.Metrics.SwitchPorts...)
+	metrics = append(metrics, c.Metrics.Hosts...)
+	metrics = append(metrics, c.Metrics.Ports...)
+	metrics = append(metrics, c.Metrics.Nodes...)
+	metrics = append(metrics, c.Metrics.IOGroups...)
+	metrics = append(metrics, c.Metrics.ManagedDisks.Metrics...)
+	metrics = append(metrics, c.Metrics.Volumes.Metrics...)
+	return metrics
 }
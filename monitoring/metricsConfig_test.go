@@ -0,0 +1,68 @@
+package monitoring
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetConfErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics_conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]string{
+		"invalid unit": `
+metrics:
+  pools:
+    properties:
+      - property_name: Capacity
+        prometheus_name: storage_usable_capacity_bytes
+        unit: parsecs
+`,
+		"invalid label": `
+metrics:
+  pools:
+    property_groups:
+      - prometheus_name: storage_pool_tier_capacity_bytes
+        label: storage tier
+        properties:
+          - property_name: Tier 0 Flash Capacity
+            label_value: tier0_flash
+`,
+		"duplicate label value": `
+metrics:
+  disks:
+    property_groups:
+      - prometheus_name: storage_disk_tier_capacity_bytes
+        label: tier
+        properties:
+          - property_name: Tier 0 Flash Capacity
+            label_value: flash
+          - property_name: Tier 1 Flash Capacity
+            label_value: flash
+`,
+	}
+
+	for name, config := range tests {
+		path := filepath.Join(dir, "metrics_conf.yaml")
+		if err := ioutil.WriteFile(path, []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		var metricsConfig MetricsConfig
+		if err := metricsConfig.GetConf(path); err == nil {
+			t.Errorf("GetConf() expected an error for the %s", name)
+		}
+	}
+}
+
+func TestGetConf(t *testing.T) {
+	var metricsConfig MetricsConfig
+	if err := metricsConfig.GetConf("../metrics_conf.yaml"); err != nil {
+		t.Fatalf("GetConf() returned an error for the default configuration: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package monitoring
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ServersConfig : Struct to represent the IBM Spectrum servers config file
+type ServersConfig struct {
+	Servers []ServerConfig `yaml:"servers"`
+}
+
+// ServerConfig : IBM Spectrum Control server to monitor
+type ServerConfig struct {
+	Name     string `yaml:"name"`
+	BaseURL  string `yaml:"base_url"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	TLS      struct {
+		CAFile             string `yaml:"ca_file"`
+		ServerFingerprint  string `yaml:"server_fingerprint"`
+		CertFile           string `yaml:"cert_file"`
+		KeyFile            string `yaml:"key_file"`
+		MinVersion         string `yaml:"min_version"`
+		InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	} `yaml:"tls"`
+	// Collectors overrides the collector flags for this server, by collector name
+	Collectors map[string]CollectorConfig `yaml:"collectors"`
+}
+
+// CollectorConfig : collector settings of a server, the flags are used when omitted
+type CollectorConfig struct {
+	Enabled *bool   `yaml:"enabled"`
+	Filter  *string `yaml:"filter"`
+}
+
+// GetConf file from the given path
+func (c *ServersConfig) GetConf(filePath string) error {
+	yamlFile, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = yaml.UnmarshalStrict(yamlFile, c)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, server := range c.Servers {
+		if server.Name == "" || server.BaseURL == "" {
+			return fmt.Errorf("name and base_url are mandatory for each server")
+		}
+		if names[server.Name] {
+			return fmt.Errorf("server %s is defined twice", server.Name)
+		}
+		names[server.Name] = true
+	}
+	return nil
+}
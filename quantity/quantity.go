@@ -0,0 +1,192 @@
+// Package quantity parses the values of the IBM Spectrum properties, e.g. "12.5 TiB", "35%", "3.2:1" or
+// "1,024.00", into Prometheus base units : bytes, bits per second and ratios. The numbers are formatted
+// according to the locale of the IBM Spectrum server, with a decimal point by default.
+package quantity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrNoValue is returned for the placeholders IBM Spectrum uses when a value is not available
+var ErrNoValue = errors.New("no value")
+
+// placeholders are the values meaning that a property is not available
+var placeholders = map[string]bool{
+	"":               true,
+	"-":              true,
+	"--":             true,
+	"n/a":            true,
+	"na":             true,
+	"none":           true,
+	"unknown":        true,
+	"not available":  true,
+	"not applicable": true,
+}
+
+// units are the factors converting a unit to the base unit
+var units = map[string]float64{
+	"b":     1,
+	"byte":  1,
+	"bytes": 1,
+
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+
+	"kb": 1e3,
+	"mb": 1e6,
+	"gb": 1e9,
+	"tb": 1e12,
+	"pb": 1e15,
+	"eb": 1e18,
+
+	// the link speeds are converted to bits per second
+	"bps":  1,
+	"kbps": 1e3,
+	"mbps": 1e6,
+	"gbps": 1e9,
+
+	"%":       0.01,
+	"percent": 0.01,
+	"ratio":   1,
+}
+
+// Locale is the formatting of the numbers, told apart by their decimal separator
+type Locale string
+
+const (
+	// DecimalPoint formats the numbers as 1,024.5
+	DecimalPoint Locale = "."
+	// DecimalComma formats the numbers as 1.024,5
+	DecimalComma Locale = ","
+)
+
+// Valid tells whether the locale is known
+func (l Locale) Valid() bool {
+	return l == DecimalPoint || l == DecimalComma
+}
+
+// thousandsSeparator returns the separator of the thousands other than the spaces and the apostrophes
+func (l Locale) thousandsSeparator() string {
+	if l == DecimalComma {
+		return "."
+	}
+	return ","
+}
+
+// IsPlaceholder tells whether the value is one of the placeholders of the values not available
+func IsPlaceholder(value string) bool {
+	return placeholders[strings.ToLower(strings.TrimSpace(value))]
+}
+
+// ValidUnit tells whether the unit is known, the empty unit meaning no conversion
+func ValidUnit(unit string) bool {
+	_, found := units[strings.ToLower(unit)]
+	return unit == "" || found
+}
+
+// Parse parses a value formatted with a decimal point into base units
+func Parse(value, hint string) (float64, error) {
+	return DecimalPoint.Parse(value, hint)
+}
+
+// Parse parses a value into base units. The unit of the value is used when given, otherwise the unit hint.
+// A value without unit nor hint is returned as is.
+func (l Locale) Parse(value, hint string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if IsPlaceholder(value) {
+		return 0, ErrNoValue
+	}
+
+	// a ratio such as 3.2:1
+	if i := strings.Index(value, ":"); i >= 0 {
+		numerator, err := l.parseNumber(value[:i])
+		if err != nil {
+			return 0, fmt.Errorf("invalid ratio %q: %v", value, err)
+		}
+		denominator, err := l.parseNumber(value[i+1:])
+		if err != nil || denominator == 0 {
+			return 0, fmt.Errorf("invalid ratio %q", value)
+		}
+		return numerator / denominator, nil
+	}
+
+	number, unit := splitUnit(value)
+	if unit == "" {
+		unit = hint
+	}
+
+	factor := 1.0
+	if unit != "" {
+		var found bool
+		factor, found = units[strings.ToLower(unit)]
+		if !found {
+			return 0, fmt.Errorf("unknown unit %q in %q", unit, value)
+		}
+	}
+
+	parsed, err := l.parseNumber(number)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q: %v", value, err)
+	}
+	if factor < 1 {
+		// dividing keeps 35% exactly 0.35
+		return parsed / (1 / factor), nil
+	}
+	return parsed * factor, nil
+}
+
+// splitUnit splits a value into its number and its unit
+func splitUnit(value string) (string, string) {
+	i := strings.LastIndexFunc(value, func(r rune) bool {
+		return unicode.IsDigit(r) || r == '.' || r == ','
+	})
+	return strings.TrimSpace(value[:i+1]), strings.TrimSpace(value[i+1:])
+}
+
+// parseNumber parses a number formatted with the decimal separator of the locale and thousands separators
+func (l Locale) parseNumber(number string) (float64, error) {
+	number = strings.Map(func(r rune) rune {
+		// the spaces, including the non breaking ones, and the apostrophes separate the thousands
+		if unicode.IsSpace(r) || r == '\'' {
+			return -1
+		}
+		return r
+	}, number)
+
+	integer, fraction := number, ""
+	if i := strings.Index(number, string(l)); i >= 0 {
+		integer, fraction = number[:i], "."+number[i+1:]
+	}
+
+	if separator := l.thousandsSeparator(); strings.Contains(integer, separator) {
+		if !thousandsGrouped(integer, separator) {
+			return 0, fmt.Errorf("digits not grouped by thousands in %q", number)
+		}
+		integer = strings.ReplaceAll(integer, separator, "")
+	}
+
+	return strconv.ParseFloat(integer+fraction, 64)
+}
+
+// thousandsGrouped tells whether the digits of an integer are grouped by thousands, e.g. 1,024,000 but
+// neither 0,125 nor 12,5
+func thousandsGrouped(integer, separator string) bool {
+	groups := strings.Split(strings.TrimLeft(integer, "+-"), separator)
+	if len(groups[0]) == 0 || len(groups[0]) > 3 || groups[0][0] == '0' {
+		return false
+	}
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package quantity
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value, hint string
+		expected    float64
+	}{
+		{"2,299.48", "", 2299.48},
+		{"1,024", "", 1024},
+		{"1,500", "", 1500},
+		{"1.024", "", 1.024},
+		{"1,024,000", "", 1024000},
+		{"1 024.5", "", 1024.5},
+		{"1'024.5", "", 1024.5},
+		{"0.125 TiB", "", 0.125 * (1 << 40)},
+		{"12.5 TiB", "", 12.5 * (1 << 40)},
+		{"12.5TiB", "GiB", 12.5 * (1 << 40)},
+		{"100.00 GiB", "", 100 * (1 << 30)},
+		{"2 GB", "", 2e9},
+		{"512 bytes", "", 512},
+		{"2,299.48", "GiB", 2299.48 * (1 << 30)},
+		{"35%", "", 0.35},
+		{"35", "percent", 0.35},
+		{"3.2:1", "", 3.2},
+		{"-4.5", "", -4.5},
+		{"16 Gbps", "", 16e9},
+		{"8", "Gbps", 8e9},
+	}
+
+	for _, test := range tests {
+		value, err := Parse(test.value, test.hint)
+		if err != nil {
+			t.Errorf("Parse(%q, %q) returned an error: %v", test.value, test.hint, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("Parse(%q, %q) = %v, expected %v", test.value, test.hint, value, test.expected)
+		}
+	}
+}
+
+func TestParseDecimalComma(t *testing.T) {
+	tests := []struct {
+		value, hint string
+		expected    float64
+	}{
+		{"0,125 TiB", "", 0.125 * (1 << 40)},
+		{"1,500", "", 1.5},
+		{"1,024", "", 1.024},
+		{"1.024", "", 1024},
+		{"1.024.000", "", 1024000},
+		{"1.024,50", "", 1024.5},
+		{"12,5", "", 12.5},
+		{"1 024,5", "", 1024.5},
+		{"2.299,48", "GiB", 2299.48 * (1 << 30)},
+		{"3,2:1", "", 3.2},
+		{"-4,5", "", -4.5},
+	}
+
+	for _, test := range tests {
+		value, err := DecimalComma.Parse(test.value, test.hint)
+		if err != nil {
+			t.Errorf("DecimalComma.Parse(%q, %q) returned an error: %v", test.value, test.hint, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("DecimalComma.Parse(%q, %q) = %v, expected %v", test.value, test.hint, value, test.expected)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, placeholder := range []string{"", "-", " N/A ", "Unknown"} {
+		if _, err := Parse(placeholder, ""); err != ErrNoValue {
+			t.Errorf("Parse(%q) = %v, expected ErrNoValue", placeholder, err)
+		}
+	}
+
+	// the numbers formatted with a decimal comma are rejected rather than read as thousands
+	for _, invalid := range []string{"12.5 parsecs", "Online", "3:0", "1.2.3,4,5", "0,125 TiB", "12,5", "1.024,50",
+		"1,02"} {
+		if _, err := Parse(invalid, ""); err == nil || err == ErrNoValue {
+			t.Errorf("Parse(%q) expected an error, got %v", invalid, err)
+		}
+	}
+
+	for _, invalid := range []string{"1,024.5", "0.125", "1,2,3"} {
+		if _, err := DecimalComma.Parse(invalid, ""); err == nil || err == ErrNoValue {
+			t.Errorf("DecimalComma.Parse(%q) expected an error, got %v", invalid, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package spectrumservice
+
+import (
+	"context"
+)
+
+// API is the IBM Spectrum API used by the collectors.
+// Client implements it, other implementations can replay recorded responses, fan out to several servers
+// or decorate a client.
+type API interface {
+	CollectFromStorage(ctx context.Context, filter string) (*CollectedStorageMetrics, error)
+	CollectFromVolumes(ctx context.Context, filter string) (*CollectedVolumeMetrics, error)
+	CollectFromSwitch(ctx context.Context, filter string) (*CollectedSwitchMetrics, error)
+	CollectFromSwitchPorts(ctx context.Context, filter string) (*CollectedSwitchPortMetrics, error)
+	CollectFromFabrics(ctx context.Context, filter string) (*CollectedFabricMetrics, error)
+	CollectFromPools(ctx context.Context, filter string) (*CollectedPoolMetrics, error)
+	CollectFromHosts(ctx context.Context, filter string) (*CollectedHostMetrics, error)
+	CollectFromPorts(ctx context.Context, filter string) (*CollectedPortMetrics, error)
+	CollectFromNodes(ctx context.Context, filter string) (*CollectedNodeMetrics, error)
+	CollectFromManagedDisks(ctx context.Context, filter string) (*CollectedManagedDiskMetrics, error)
+	CollectFromDisks(ctx context.Context, filter string) (*CollectedDiskMetrics, error)
+	// MetricDetail describes a metric from the catalog returned by IBM Spectrum
+	MetricDetail(metricID int) (MetricDetail, bool)
+}
+
+var _ API = (*Client)(nil)
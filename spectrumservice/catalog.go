@@ -0,0 +1,76 @@
+package spectrumservice
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// decodePerformance decodes a performance response. Its first element describes the metrics available for the
+// resource type and updates the catalog, the other ones are the metric values.
+func (c *Client) decodePerformance(response []byte, paramMap map[string]string) ([]MetricValue, error) {
+	var objArray []*json.RawMessage
+	err := json.Unmarshal(response, &objArray)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(objArray) > 0 && objArray[0] != nil {
+		var details MetricDetails
+		if err := json.Unmarshal(*objArray[0], &details); err == nil && len(details.Metrics) > 0 {
+			c.updateCatalog(details, paramMap["metrics"])
+		}
+	}
+
+	var metricsValue []MetricValue
+	for i := 1; i < len(objArray); i++ {
+		var metricValue MetricValue
+		err = json.Unmarshal(*objArray[i], &metricValue)
+		if err != nil {
+			return nil, err
+		}
+		metricsValue = append(metricsValue, metricValue)
+	}
+
+	return metricsValue, nil
+}
+
+// updateCatalog adds the metrics details to the catalog and warns once about the requested metrics unknown
+// by IBM Spectrum for this resource type
+func (c *Client) updateCatalog(details MetricDetails, requested string) {
+	c.catalogMutex.Lock()
+	defer c.catalogMutex.Unlock()
+
+	if c.catalog == nil {
+		c.catalog = make(map[int]MetricDetail)
+		c.unknown = make(map[int]bool)
+	}
+
+	for key, detail := range details.Metrics {
+		metricID, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		c.catalog[metricID] = detail
+	}
+
+	for _, key := range strings.Split(requested, ",") {
+		metricID, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		if _, found := details.Metrics[key]; !found && !c.unknown[metricID] {
+			c.unknown[metricID] = true
+			c.Sugar.Warnf("Metric ID %d is not in the IBM Spectrum catalog, check the metrics configuration.", metricID)
+		}
+	}
+}
+
+// MetricDetail returns the name, description and units of a metric, as described by IBM Spectrum
+func (c *Client) MetricDetail(metricID int) (MetricDetail, bool) {
+	c.catalogMutex.RLock()
+	defer c.catalogMutex.RUnlock()
+
+	detail, found := c.catalog[metricID]
+	return detail, found
+}
@@ -2,13 +2,12 @@ package spectrumservice
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -18,6 +17,7 @@ import (
 
 	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 
 	"github.com/topine/ibm-spectrum-exporter/monitoring"
 )
@@ -25,19 +25,45 @@ import (
 const (
 	// docs https://www.ibm.com/support/knowledgecenter/SS5R93_5.3.6/com.ibm.spectrum.sc.doc/mgr_rest_api_retrieving_cli.html
 	authenticate = "/srm/j_security_check"
+	logout       = "/srm/ibm_security_logout"
 	// Storage Systems
 	listStorageSystems       = "/srm/REST/api/v1/StorageSystems"
 	storageSystemPerformance = "/srm/REST/api/v1/StorageSystems/Performance"
 	listVolumes              = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Volumes"
 	volumesPerformance       = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Volumes/Performance"
 	// Switches
-	listSwitches      = "/srm/REST/api/v1/Switches"
-	switchPerformance = "/srm/REST/api/v1/Switches/Performance"
+	listSwitches           = "/srm/REST/api/v1/Switches"
+	switchPerformance      = "/srm/REST/api/v1/Switches/Performance"
+	listSwitchPorts        = "/srm/REST/api/v1/Switches/{switchID}/Ports"
+	switchPortsPerformance = "/srm/REST/api/v1/Switches/{switchID}/Ports/Performance"
+	// Fabrics
+	listFabrics = "/srm/REST/api/v1/Fabrics"
 	// Pools
 	listPools = "/srm/REST/api/v1/Pools"
+	// Hosts
+	listHosts        = "/srm/REST/api/v1/Hosts"
+	hostsPerformance = "/srm/REST/api/v1/Hosts/Performance"
+	listHostVolumes  = "/srm/REST/api/v1/Hosts/{hostID}/Volumes"
+	// Storage System Ports
+	listPorts        = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Ports"
+	portsPerformance = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Ports/Performance"
+	// Storage System Nodes and I/O Groups
+	listNodes           = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Nodes"
+	nodesPerformance    = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Nodes/Performance"
+	listIOGroups        = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/IOGroups"
+	ioGroupsPerformance = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/IOGroups/Performance"
+	// Storage System Managed Disks, or Ranks for DS8000
+	listManagedDisks        = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/ManagedDisks"
+	managedDisksPerformance = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/ManagedDisks/Performance"
+	listRanks               = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Ranks"
+	ranksPerformance        = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Ranks/Performance"
+	// Storage System Disks
+	listDisks = "/srm/REST/api/v1/StorageSystems/{storageSystemID}/Disks"
 )
 
 type Client struct {
+	// Name identifies the IBM Spectrum server, e.g. in the cache keys and the metrics
+	Name         string
 	Sugar        *zap.SugaredLogger
 	Config       monitoring.MetricsConfig
 	Username     string
@@ -45,129 +71,257 @@ type Client struct {
 	BaseURL      string
 	LocalCache   *cache.Cache
 	CacheMetrics bool
-	httpClient   *http.Client
+	Retry        RetryPolicy
+	// Concurrency is the number of storage systems collected in parallel
+	Concurrency int
+	httpClient  *http.Client
+	// inFlight limits the concurrent requests sent to IBM Spectrum
+	inFlight chan struct{}
+	// limiters limits the rate of the requests, by endpoint family
+	limiters map[string]*rate.Limiter
+
+	// catalog of the metrics described by IBM Spectrum, by metric ID
+	catalogMutex sync.RWMutex
+	catalog      map[int]MetricDetail
+	unknown      map[int]bool
+
+	// session state, shared by all the collectors using this client
+	sessionMutex sync.Mutex
+	loggedIn     bool
+	generation   int
 }
 
 func NewClient(sugar *zap.SugaredLogger, config monitoring.MetricsConfig, localCache *cache.Cache, cacheMetrics bool,
-	usr, pwd, baseURL string) *Client {
+	usr, pwd, baseURL string, tlsOptions TLSOptions) (*Client, error) {
+	tlsConfig, err := newTLSConfig(sugar, tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+
 	netTransport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint (for now mandatory as we will not have a valid certificate )
+		TLSClientConfig: tlsConfig,
 	}
+	// the cookie jar keeps the session cookies between the calls
+	jar, _ := cookiejar.New(nil)
 	netClient := &http.Client{
 		Timeout:   time.Second * 300,
 		Transport: netTransport,
+		Jar:       jar,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
 	}
 
 	return &Client{Sugar: sugar, Config: config, Username: usr, Password: pwd,
-		BaseURL: baseURL, LocalCache: localCache, CacheMetrics: cacheMetrics, httpClient: netClient}
+		BaseURL: baseURL, LocalCache: localCache, CacheMetrics: cacheMetrics, Retry: DefaultRetryPolicy,
+		Concurrency: 1, httpClient: netClient}, nil
 }
 
-func (c *Client) CollectFromStorage(filter string) (*CollectedStorageMetrics, error) {
+func (c *Client) CollectFromStorage(ctx context.Context, filter string) (*CollectedStorageMetrics, error) {
 	if c.CacheMetrics {
-		if x, found := c.LocalCache.Get("collectedMetrics"); found {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedMetrics")); found {
 			return x.(*CollectedStorageMetrics), nil
 		}
 		return nil, errors.New("metrics not found in cache")
 	}
-	return c.CollectStorageMetrics(filter)
+	return c.CollectStorageMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromVolumes(ctx context.Context, filter string) (*CollectedVolumeMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedVolumeMetrics")); found {
+			return x.(*CollectedVolumeMetrics), nil
+		}
+		return nil, errors.New("volume metrics not found in cache")
+	}
+	return c.CollectVolumeMetrics(ctx, filter)
 }
 
-func (c *Client) CollectFromSwitch(filter string) (*CollectedSwitchMetrics, error) {
+func (c *Client) CollectFromHosts(ctx context.Context, filter string) (*CollectedHostMetrics, error) {
 	if c.CacheMetrics {
-		if x, found := c.LocalCache.Get("collectedSwitchMetrics"); found {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedHostMetrics")); found {
+			return x.(*CollectedHostMetrics), nil
+		}
+		return nil, errors.New("host metrics not found in cache")
+	}
+	return c.CollectHostMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromPorts(ctx context.Context, filter string) (*CollectedPortMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedPortMetrics")); found {
+			return x.(*CollectedPortMetrics), nil
+		}
+		return nil, errors.New("port metrics not found in cache")
+	}
+	return c.CollectPortMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromNodes(ctx context.Context, filter string) (*CollectedNodeMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedNodeMetrics")); found {
+			return x.(*CollectedNodeMetrics), nil
+		}
+		return nil, errors.New("node metrics not found in cache")
+	}
+	return c.CollectNodeMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromManagedDisks(ctx context.Context, filter string) (*CollectedManagedDiskMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedManagedDiskMetrics")); found {
+			return x.(*CollectedManagedDiskMetrics), nil
+		}
+		return nil, errors.New("managed disk metrics not found in cache")
+	}
+	return c.CollectManagedDiskMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromDisks(ctx context.Context, filter string) (*CollectedDiskMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedDiskMetrics")); found {
+			return x.(*CollectedDiskMetrics), nil
+		}
+		return nil, errors.New("disk metrics not found in cache")
+	}
+	return c.CollectDiskMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromSwitch(ctx context.Context, filter string) (*CollectedSwitchMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedSwitchMetrics")); found {
 			return x.(*CollectedSwitchMetrics), nil
 		}
 		return nil, errors.New(" Switch metrics not found in cache")
 	}
-	return c.CollectSwitchMetrics(filter)
+	return c.CollectSwitchMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromSwitchPorts(ctx context.Context, filter string) (*CollectedSwitchPortMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedSwitchPortMetrics")); found {
+			return x.(*CollectedSwitchPortMetrics), nil
+		}
+		return nil, errors.New("switch port metrics not found in cache")
+	}
+	return c.CollectSwitchPortMetrics(ctx, filter)
+}
+
+func (c *Client) CollectFromFabrics(ctx context.Context, filter string) (*CollectedFabricMetrics, error) {
+	if c.CacheMetrics {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedFabricMetrics")); found {
+			return x.(*CollectedFabricMetrics), nil
+		}
+		return nil, errors.New("fabric metrics not found in cache")
+	}
+	return c.CollectFabrics(ctx, filter)
 }
 
-func (c *Client) CollectFromPools(filter string) (*CollectedPoolMetrics, error) {
+func (c *Client) CollectFromPools(ctx context.Context, filter string) (*CollectedPoolMetrics, error) {
 	if c.CacheMetrics {
-		if x, found := c.LocalCache.Get("collectedPoolMetrics"); found {
+		if x, found := c.LocalCache.Get(c.cacheKey("collectedPoolMetrics")); found {
 			return x.(*CollectedPoolMetrics), nil
 		}
 		return nil, errors.New(" Switch metrics not found in cache")
 	}
-	return c.CollectPools(filter)
+	return c.CollectPools(ctx, filter)
 }
 
-func (c *Client) CollectAndCacheMetrics(filters map[string]*string, collectorsState map[string]*bool) error {
-	var err error
+func (c *Client) CollectAndCacheMetrics(ctx context.Context, filters map[string]*string, collectorsState map[string]*bool) error {
 	var wg sync.WaitGroup
+	// the collections run in parallel, the error returned is the last one
+	var errMutex sync.Mutex
+	var err error
 
-	wg.Add(len(collectorsState))
-
-	if *collectorsState["storage"] {
-		go func() {
-			defer wg.Done()
-			collectedMetrics, errStorage := c.CollectStorageMetrics(*filters["storage"])
-			if errStorage != nil {
-				//c.LocalCache.Delete("collectedMetrics")
-				c.Sugar.Error("Error Collecting metrics for cache.", errStorage)
-				err = errStorage
-			}
-			c.LocalCache.Set("collectedMetrics", collectedMetrics, -1)
-		}()
-	}
-
-	if *collectorsState["switch"] {
+	// collect runs the collection of an enabled collector and caches its result
+	collect := func(collector, key, description string, collection func(filter string) (interface{}, error)) {
+		if !*collectorsState[collector] {
+			return
+		}
+		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			collectedSwitchMetrics, errSwitches := c.CollectSwitchMetrics(*filters["switch"])
-			if errSwitches != nil {
-				//c.LocalCache.Delete("collectedMetrics")
-				c.Sugar.Error("Error Collecting switches metrics for cache.", errSwitches)
-				err = errSwitches
+			collected, errCollection := collection(*filters[collector])
+			if errCollection != nil {
+				c.Sugar.Errorf("Error Collecting %smetrics for cache. %v", description, errCollection)
+				errMutex.Lock()
+				err = errCollection
+				errMutex.Unlock()
 			}
-			c.LocalCache.Set("collectedSwitchMetrics", collectedSwitchMetrics, -1)
+			c.cacheCollection(key, collected, errCollection)
 		}()
 	}
 
-	if *collectorsState["pool"] {
-		go func() {
-			defer wg.Done()
-			collectedPoolMetrics, errPool := c.CollectPools(*filters["pool"])
-			if errPool != nil {
-				//c.LocalCache.Delete("collectedMetrics")
-				c.Sugar.Error("Error Collecting pool metrics for cache.", errPool)
-				err = errPool
-			}
-			c.LocalCache.Set("collectedPoolMetrics", collectedPoolMetrics, -1)
-		}()
-	}
+	collect("storage", "collectedMetrics", "", func(filter string) (interface{}, error) {
+		return c.CollectStorageMetrics(ctx, filter)
+	})
+	collect("volume", "collectedVolumeMetrics", "volume ", func(filter string) (interface{}, error) {
+		return c.CollectVolumeMetrics(ctx, filter)
+	})
+	collect("host", "collectedHostMetrics", "host ", func(filter string) (interface{}, error) {
+		return c.CollectHostMetrics(ctx, filter)
+	})
+	collect("port", "collectedPortMetrics", "port ", func(filter string) (interface{}, error) {
+		return c.CollectPortMetrics(ctx, filter)
+	})
+	collect("node", "collectedNodeMetrics", "node ", func(filter string) (interface{}, error) {
+		return c.CollectNodeMetrics(ctx, filter)
+	})
+	collect("mdisk", "collectedManagedDiskMetrics", "managed disk ", func(filter string) (interface{}, error) {
+		return c.CollectManagedDiskMetrics(ctx, filter)
+	})
+	collect("disk", "collectedDiskMetrics", "disk ", func(filter string) (interface{}, error) {
+		return c.CollectDiskMetrics(ctx, filter)
+	})
+	collect("switch", "collectedSwitchMetrics", "switches ", func(filter string) (interface{}, error) {
+		return c.CollectSwitchMetrics(ctx, filter)
+	})
+	collect("switch_port", "collectedSwitchPortMetrics", "switch port ", func(filter string) (interface{}, error) {
+		return c.CollectSwitchPortMetrics(ctx, filter)
+	})
+	collect("fabric", "collectedFabricMetrics", "fabric ", func(filter string) (interface{}, error) {
+		return c.CollectFabrics(ctx, filter)
+	})
+	collect("pool", "collectedPoolMetrics", "pool ", func(filter string) (interface{}, error) {
+		return c.CollectPools(ctx, filter)
+	})
 	wg.Wait()
 	return err
 }
 
-func (c *Client) CollectStorageMetrics(filter string) (*CollectedStorageMetrics, error) {
-	begin := time.Now()
-	var response []*StorageMetrics //nolint prealloc
-	cookies, err := c.authenticate()
-	if err != nil {
-		c.Sugar.Error("Error during authentication.", err)
-		return nil, err
+// cacheCollection caches the collected metrics. After a transient error the previous collection is kept,
+// otherwise it is removed so that the collectors report the failure.
+func (c *Client) cacheCollection(key string, collected interface{}, err error) {
+	switch {
+	case err == nil:
+		c.LocalCache.Set(c.cacheKey(key), collected, cache.NoExpiration)
+	case IsRetryable(err):
+		c.Sugar.Warnf("Keeping the previous %s after a transient error.", key)
+	default:
+		c.LocalCache.Delete(c.cacheKey(key))
 	}
+}
+
+// cacheKey returns the key of the cache entry for this server, the cache being shared by all the clients
+func (c *Client) cacheKey(key string) string {
+	return c.Name + "/" + key
+}
 
-	storages, err := c.listStorageSystems(cookies, filter)
+func (c *Client) CollectStorageMetrics(ctx context.Context, filter string) (*CollectedStorageMetrics, error) {
+	begin := time.Now()
+	var response []*StorageMetrics //nolint prealloc
+	storages, err := c.listStorageSystems(ctx, filter)
 	if err != nil {
 		c.Sugar.Error("Error getting storage systems list.", err)
 		return nil, err
 	}
 
-	paramsMap := make(map[string]string)
-
 	// will get all the stats from the last 10 minutes
 	// metrics seems to be delyed
 	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
 
-	paramsMap["startTime"] = strconv.FormatInt(timeInMillis, 10)
-
 	var storageBuffer bytes.Buffer
-	for _, metric := range c.Config.Metrics.StorageSystems {
+	for _, metric := range c.Config.Metrics.StorageSystems.Metrics {
 		storageBuffer.WriteString(strconv.Itoa(metric.MetricID))
 		storageBuffer.WriteString(",")
 	}
@@ -178,53 +332,224 @@ func (c *Client) CollectStorageMetrics(filter string) (*CollectedStorageMetrics,
 		volumeBuffer.WriteString(",")
 	}
 
-	paramsMap["metrics"] = storageBuffer.String() + volumeBuffer.String()
-	paramsMap["granularity"] = "sample"
 	// Spectrum sometimes is not returning the values if asking for all storage at once
-	for _, storage := range storages {
-		storageID := storage.ID
-		storageName := storage.Name
+	// the storage systems are collected one by one, each one keeping its position in the result
+	results := make([]*StorageMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		storageMetrics, err := c.collectStorageSystem(ctx, storages[i], timeInMillis,
+			storageBuffer.String()+volumeBuffer.String())
+		results[i] = storageMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, storageMetrics := range results {
+		if storageMetrics != nil {
+			response = append(response, storageMetrics)
+		}
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedStorageMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectStorageSystem collects the metrics of one storage system
+func (c *Client) collectStorageSystem(ctx context.Context, storage StorageSystem, startTime int64,
+	metricIDs string) (*StorageMetrics, error) {
+	// without performance metrics configured, only the storage system properties are collected
+	if metricIDs == "" {
+		return &StorageMetrics{Storage: storage}, nil
+	}
+
+	paramsMap := make(map[string]string)
+	paramsMap["startTime"] = strconv.FormatInt(startTime, 10)
+	paramsMap["granularity"] = "sample"
+	paramsMap["metrics"] = metricIDs
+	paramsMap["ids"] = storage.ID
+
+	storageMetrics, err := c.collectStorageSystemMetrics(ctx, storage.ID, paramsMap)
+	if IsNotFound(err) {
+		c.Sugar.Warnf("Storage system %s not found, it may have been removed.", storage.Name)
+		return nil, err
+	}
+	if err != nil {
+		// the storage system is kept without metrics, so that its status is still exported
+		c.Sugar.Errorf("Error retrieving the metrics of storage system %s. %v", storage.Name, err)
+		return &StorageMetrics{Storage: storage}, err
+	}
+
+	return &StorageMetrics{
+		Storage:              storage,
+		StorageSystemMetrics: storageMetrics}, nil
+}
+
+// CollectVolumeMetrics collects the properties and metrics of the volumes matching the filter,
+// on all the storage systems
+func (c *Client) CollectVolumeMetrics(ctx context.Context, filter string) (*CollectedVolumeMetrics, error) {
+	begin := time.Now()
+	var response []*VolumeMetrics //nolint prealloc
+	storages, err := c.listStorageSystems(ctx, ".*")
+	if err != nil {
+		c.Sugar.Error("Error getting storage systems list.", err)
+		return nil, err
+	}
+
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
 
-		paramsMap["ids"] = storageID
+	metricIDs := joinMetricIDs(c.Config.Metrics.Volumes.Metrics)
 
-		storageMetrics, err := c.collectStorageSystemMetrics(cookies, storageID, paramsMap)
+	results := make([][]*VolumeMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		volumeMetrics, err := c.collectStorageVolumes(ctx, storages[i], timeInMillis, metricIDs, filter)
+		results[i] = volumeMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, volumeMetrics := range results {
+		response = append(response, volumeMetrics...)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedVolumeMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectStorageVolumes collects the metrics of the volumes of a storage system matching the filter
+func (c *Client) collectStorageVolumes(ctx context.Context, storage StorageSystem, startTime int64,
+	metricIDs, filter string) ([]*VolumeMetrics, error) {
+	volumes, err := c.listVolumes(ctx, storage.ID)
+	if IsNotFound(err) {
+		c.Sugar.Warnf("Storage system %s not found, it may have been removed.", storage.Name)
+		return nil, err
+	}
+	if err != nil {
+		c.Sugar.Errorf("Error listing volumes for storage %s. %v", storage.Name, err)
+		return nil, err
+	}
+
+	var response []*VolumeMetrics
+	byID := make(map[string]*VolumeMetrics)
+	for _, volume := range volumes {
+		matched, err := regexp.MatchString(filter, strings.ToUpper(volume.Name))
 		if err != nil {
-			c.Sugar.Error("Error retrieving storage system metrics.", err)
-			continue
+			c.Sugar.Error("Error matching regex.", err)
+			return nil, err
 		}
+		if matched {
+			volumeMetrics := &VolumeMetrics{Storage: storage, Volume: volume}
+			response = append(response, volumeMetrics)
+			byID[volume.ID] = volumeMetrics
+		}
+	}
+	// without performance metrics configured, only the volume properties are collected
+	if len(response) == 0 || metricIDs == "" {
+		return response, nil
+	}
 
-		volumesMap, err := c.listVolumes(cookies, storageID)
-		if err != nil {
-			c.Sugar.Errorf("Error listing volumes for storage %s. %v", storageName,
-				err)
-			continue
+	paramsMap := make(map[string]string)
+	paramsMap["startTime"] = strconv.FormatInt(startTime, 10)
+	paramsMap["granularity"] = "sample"
+	paramsMap["metrics"] = metricIDs
+
+	metricsValue, err := c.collectVolumeMetrics(ctx, storage.ID, paramsMap)
+	if err != nil {
+		c.Sugar.Errorf("Error collecting volumes metrics for storage %s. %v", storage.Name, err)
+		return nil, err
+	}
+
+	// the values are matched to the volumes by id, the volume names are not unique
+	for _, metricValue := range metricsValue {
+		if volumeMetrics, found := byID[strconv.Itoa(metricValue.DeviceID)]; found {
+			volumeMetrics.VolumeMetrics = append(volumeMetrics.VolumeMetrics, metricValue)
 		}
+	}
 
-		delete(paramsMap, "ids")
-		paramsMap["metrics"] = volumeBuffer.String()
-		volumesMetrics, err := c.collectVolumeMetrics(cookies, storageID, paramsMap)
-		if err != nil {
-			c.Sugar.Errorf("Error collecting volumes metrics for storage %s. %v", storageName,
-				err)
-			continue
+	return response, nil
+}
+
+// forEach calls collect for the resources 0 to n-1, the configured number of resources being collected
+// in parallel. An authentication failure stops the workers and is returned, the other errors only skip a resource.
+func (c *Client) forEach(ctx context.Context, n int, collect func(ctx context.Context, i int) error) error {
+	collectCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var abortOnce sync.Once
+	var abortErr error
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < c.workers(n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := collect(collectCtx, i); IsUnauthorized(err) {
+					abortOnce.Do(func() {
+						abortErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case jobs <- i:
+		case <-collectCtx.Done():
+			break dispatch
 		}
+	}
+	close(jobs)
+	wg.Wait()
 
-		response = append(response,
-			&StorageMetrics{
-				Storage:              storage,
-				VolumeMap:            volumesMap,
-				StorageSystemMetrics: storageMetrics,
-				VolumeMetrics:        volumesMetrics})
+	if abortErr != nil {
+		return abortErr
 	}
+	return ctx.Err()
+}
 
-	duration := time.Since(begin)
+// workers returns the number of workers collecting the given number of resources in parallel
+func (c *Client) workers(resources int) int {
+	workers := c.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > resources {
+		workers = resources
+	}
+	return workers
+}
 
-	return &CollectedStorageMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+// joinMetricIDs returns the IDs of the metrics, as expected by the metrics parameter of the performance requests
+func joinMetricIDs(metrics []monitoring.Metric) string {
+	var ids strings.Builder
+	for _, metric := range metrics {
+		ids.WriteString(strconv.Itoa(metric.MetricID))
+		ids.WriteString(",")
+	}
+	return ids.String()
 }
 
-func (c *Client) listStorageSystems(cookies []*http.Cookie, regex string) ([]StorageSystem, error) {
+// SetMaxInFlightRequests limits the number of concurrent requests sent to IBM Spectrum, 0 means no limit.
+// It must be called before the first collection.
+func (c *Client) SetMaxInFlightRequests(max int) {
+	if max <= 0 {
+		c.inFlight = nil
+		return
+	}
+	c.inFlight = make(chan struct{}, max)
+}
+
+func (c *Client) listStorageSystems(ctx context.Context, regex string) ([]StorageSystem, error) {
 	// retrieve the system storage metrics ( already aggregated )
-	responseStorage, err := c.doRequest("GET", c.BaseURL+listStorageSystems, nil, cookies, nil)
+	responseStorage, err := c.doRequest(ctx, "GET", c.BaseURL+listStorageSystems, nil, nil)
 	if err != nil {
 		c.Sugar.Error("Error requesting storage system metrics.", err)
 		return nil, err
@@ -257,45 +582,32 @@ func (c *Client) listStorageSystems(cookies []*http.Cookie, regex string) ([]Sto
 	return storages, nil
 }
 
-func (c *Client) collectStorageSystemMetrics(cookies []*http.Cookie, storageSystemID string,
+func (c *Client) collectStorageSystemMetrics(ctx context.Context, storageSystemID string,
 	paramMap map[string]string) ([]MetricValue, error) {
-	storagePerm, err := c.doRequest("GET", strings.Replace(c.BaseURL+storageSystemPerformance,
-		"{storageSystemID}", storageSystemID, -1), nil, cookies, paramMap)
+	storagePerm, err := c.doRequest(ctx, "GET", strings.Replace(c.BaseURL+storageSystemPerformance,
+		"{storageSystemID}", storageSystemID, -1), nil, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error during storage system metrics call.", err)
 		return nil, err
 	}
 
-	var objArray []*json.RawMessage
-	err = json.Unmarshal(storagePerm, &objArray)
+	metricsValue, err := c.decodePerformance(storagePerm, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error reading storage system metrics response.", err)
 		c.Sugar.Errorf("Response received: %s", string(storagePerm))
 		return nil, err
 	}
 
-	c.Sugar.Infof("Metrics received for storageID %s : %d .", storageSystemID, len(objArray)-1)
-
-	//objArray[0] are the metrics description
-	var metricsValue []MetricValue
-	for i := 1; i < len(objArray); i++ {
-		var metricValue MetricValue
-		err = json.Unmarshal(*objArray[i], &metricValue)
-		if err != nil {
-			c.Sugar.Error("Error mapping storage system metrics.", err)
-			return nil, err
-		}
-		metricsValue = append(metricsValue, metricValue)
-	}
+	c.Sugar.Infof("Metrics received for storageID %s : %d .", storageSystemID, len(metricsValue))
 
 	return metricsValue, nil
 }
 
-func (c *Client) listVolumes(cookies []*http.Cookie, storageSystemID string) (map[string]string, error) {
+func (c *Client) listVolumes(ctx context.Context, storageSystemID string) (Volumes, error) {
 	//lookup for all volumes of the storage system
-	volResponse, err := c.doRequest("GET",
+	volResponse, err := c.doRequest(ctx, "GET",
 		strings.Replace(c.BaseURL+listVolumes, "{storageSystemID}", storageSystemID, -1),
-		nil, cookies, nil)
+		nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -308,59 +620,107 @@ func (c *Client) listVolumes(cookies []*http.Cookie, storageSystemID string) (ma
 		return nil, err
 	}
 
-	volumeMap := make(map[string]string)
-	for _, volume := range data {
-		volumeMap[volume.ID] = volume.VolumeUniqueID
-	}
-
-	c.Sugar.Infof("Volumes retrieved for Storage System %s : %d", storageSystemID, len(volumeMap))
+	c.Sugar.Infof("Volumes retrieved for Storage System %s : %d", storageSystemID, len(data))
 
-	return volumeMap, nil
+	return data, nil
 }
 
-func (c *Client) collectVolumeMetrics(cookies []*http.Cookie, storageSystemID string,
+func (c *Client) collectVolumeMetrics(ctx context.Context, storageSystemID string,
 	paramMap map[string]string) ([]MetricValue, error) {
-	volumesPerm, err := c.doRequest("GET",
+	volumesPerm, err := c.doRequest(ctx, "GET",
 		strings.Replace(c.BaseURL+volumesPerformance, "{storageSystemID}", storageSystemID, -1),
-		nil, cookies, paramMap)
+		nil, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error during volume metrics call.", err)
 		return nil, err
 	}
 
-	var objArray []*json.RawMessage
-	err = json.Unmarshal(volumesPerm, &objArray)
+	metricsValue, err := c.decodePerformance(volumesPerm, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error reading volume metrics response.", err)
 		c.Sugar.Errorf("Response received: %s", string(volumesPerm))
 		return nil, err
 	}
 
-	var metricsValue []MetricValue
-	for i := 1; i < len(objArray); i++ {
-		var metricValue MetricValue
-		err = json.Unmarshal(*objArray[i], &metricValue)
-		if err != nil {
-			c.Sugar.Error("Error mapping volume system metrics.", err)
-			return nil, err
-		}
+	return metricsValue, nil
+}
+
+// listStorageResources decodes the list of the resources of a storage system, e.g. its ports, into resources
+func (c *Client) listStorageResources(ctx context.Context, path string, storage StorageSystem,
+	resources interface{}) error {
+	return c.listResources(ctx, strings.Replace(path, "{storageSystemID}", storage.ID, -1),
+		"Storage system "+storage.Name, resources)
+}
+
+// storageResourcesMetrics collects the metrics of the resources of a storage system, by resource id.
+// Nothing is requested without metric IDs.
+func (c *Client) storageResourcesMetrics(ctx context.Context, path string, storage StorageSystem, startTime int64,
+	metricIDs string) (map[string][]MetricValue, error) {
+	return c.resourcesMetrics(ctx, strings.Replace(path, "{storageSystemID}", storage.ID, -1),
+		"Storage system "+storage.Name, startTime, metricIDs)
+}
 
-		metricsValue = append(metricsValue, metricValue)
+// listResources decodes the list of the resources of a parent resource, e.g. the ports of a switch, into resources
+func (c *Client) listResources(ctx context.Context, path, parent string, resources interface{}) error {
+	response, err := c.doRequest(ctx, "GET", c.BaseURL+path, nil, nil)
+	if IsNotFound(err) {
+		c.Sugar.Warnf("%s not found, it may have been removed.", parent)
+		return err
+	}
+	if err != nil {
+		c.Sugar.Errorf("Error listing %s of %s. %v", path, parent, err)
+		return err
 	}
 
-	return metricsValue, nil
+	err = json.Unmarshal(response, resources)
+	if err != nil {
+		c.Sugar.Errorf("Error reading %s response. %v", path, err)
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return err
+	}
+
+	return nil
 }
 
-func (c *Client) CollectSwitchMetrics(filter string) (*CollectedSwitchMetrics, error) {
-	begin := time.Now()
-	var response []*SwitchMetrics //nolint prealloc
-	cookies, err := c.authenticate()
+// resourcesMetrics collects the metrics of the resources of a parent resource, by resource id.
+// Nothing is requested without metric IDs.
+func (c *Client) resourcesMetrics(ctx context.Context, path, parent string, startTime int64,
+	metricIDs string) (map[string][]MetricValue, error) {
+	byID := make(map[string][]MetricValue)
+	if metricIDs == "" {
+		return byID, nil
+	}
+
+	paramsMap := make(map[string]string)
+	paramsMap["startTime"] = strconv.FormatInt(startTime, 10)
+	paramsMap["granularity"] = "sample"
+	paramsMap["metrics"] = metricIDs
+
+	response, err := c.doRequest(ctx, "GET", c.BaseURL+path, nil, paramsMap)
 	if err != nil {
-		c.Sugar.Error("Error during authentication.", err)
+		c.Sugar.Errorf("Error collecting %s metrics of %s. %v", path, parent, err)
 		return nil, err
 	}
 
-	switches, err := c.listSwitches(cookies)
+	metricsValue, err := c.decodePerformance(response, paramsMap)
+	if err != nil {
+		c.Sugar.Errorf("Error reading %s metrics response. %v", path, err)
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return nil, err
+	}
+
+	for _, metricValue := range metricsValue {
+		id := strconv.Itoa(metricValue.DeviceID)
+		byID[id] = append(byID[id], metricValue)
+	}
+
+	return byID, nil
+}
+
+func (c *Client) CollectSwitchMetrics(ctx context.Context, filter string) (*CollectedSwitchMetrics, error) {
+	begin := time.Now()
+	var response []*SwitchMetrics //nolint prealloc
+	switches, err := c.listSwitches(ctx)
 	if err != nil {
 		c.Sugar.Error("Error getting switches list.", err)
 		return nil, err
@@ -383,6 +743,9 @@ func (c *Client) CollectSwitchMetrics(filter string) (*CollectedSwitchMetrics, e
 	paramsMap["granularity"] = "sample"
 
 	for _, s := range switches {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 
 		matched, err := regexp.MatchString(filter, strings.ToUpper(s.Name))
 		if err != nil {
@@ -398,17 +761,27 @@ func (c *Client) CollectSwitchMetrics(filter string) (*CollectedSwitchMetrics, e
 
 		paramsMap["ids"] = switchID
 
-		switchMetrics, err := c.collectSwitchMetrics(cookies, switchID, paramsMap)
+		// without performance metrics configured, only the switch inventory is collected
+		var switchMetrics []MetricValue
+		if paramsMap["metrics"] != "" {
+			switchMetrics, err = c.collectSwitchMetrics(ctx, switchID, paramsMap)
+		}
+		if IsUnauthorized(err) {
+			return nil, err
+		}
+		if IsNotFound(err) {
+			c.Sugar.Warnf("Switch %s not found, it may have been removed.", s.Name)
+			continue
+		}
 		if err != nil {
 			c.Sugar.Error("Error retrieving siwtches metrics.", err)
 			continue
 		}
 
-		if switchMetrics != nil {
-			response = append(response,
-				&SwitchMetrics{Switch: s,
-					SwitchAggregatedMetrics: switchMetrics})
-		}
+		// the switches without metric values are kept for their inventory and monitoring status
+		response = append(response,
+			&SwitchMetrics{Switch: s,
+				SwitchAggregatedMetrics: switchMetrics})
 	}
 
 	duration := time.Since(begin)
@@ -416,9 +789,9 @@ func (c *Client) CollectSwitchMetrics(filter string) (*CollectedSwitchMetrics, e
 	return &CollectedSwitchMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
 }
 
-func (c *Client) listSwitches(cookies []*http.Cookie) ([]Switch, error) {
+func (c *Client) listSwitches(ctx context.Context) ([]Switch, error) {
 	// retrieve the system storage metrics ( already aggregated )
-	responseSwitches, err := c.doRequest("GET", c.BaseURL+listSwitches, nil, cookies, nil)
+	responseSwitches, err := c.doRequest(ctx, "GET", c.BaseURL+listSwitches, nil, nil)
 	if err != nil {
 		c.Sugar.Error("Error listing switches.", err)
 		return nil, err
@@ -437,50 +810,31 @@ func (c *Client) listSwitches(cookies []*http.Cookie) ([]Switch, error) {
 	return switches, nil
 }
 
-func (c *Client) collectSwitchMetrics(cookies []*http.Cookie, switchID string,
+func (c *Client) collectSwitchMetrics(ctx context.Context, switchID string,
 	paramMap map[string]string) ([]MetricValue, error) {
 
-	switchPerm, err := c.doRequest("GET", c.BaseURL+switchPerformance, nil, cookies, paramMap)
+	switchPerm, err := c.doRequest(ctx, "GET", c.BaseURL+switchPerformance, nil, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error during switch metrics call.", err)
 		return nil, err
 	}
 
-	var objArray []*json.RawMessage
-	err = json.Unmarshal(switchPerm, &objArray)
+	metricsValue, err := c.decodePerformance(switchPerm, paramMap)
 	if err != nil {
 		c.Sugar.Error("Error reading switch metrics response.", err)
 		c.Sugar.Errorf("Response received: %s", string(switchPerm))
 		return nil, err
 	}
 
-	c.Sugar.Infof("Metrics received for switch %s : %d .", switchID, len(objArray)-1)
-
-	//objArray[0] are the metrics description
-	var metricsValue []MetricValue
-	for i := 1; i < len(objArray); i++ {
-		var metricValue MetricValue
-		err = json.Unmarshal(*objArray[i], &metricValue)
-		if err != nil {
-			c.Sugar.Error("Error mapping switches metrics.", err)
-			return nil, err
-		}
-		metricsValue = append(metricsValue, metricValue)
-	}
+	c.Sugar.Infof("Metrics received for switch %s : %d .", switchID, len(metricsValue))
 
 	return metricsValue, nil
 }
 
-func (c *Client) CollectPools(filter string) (*CollectedPoolMetrics, error) {
+func (c *Client) CollectPools(ctx context.Context, filter string) (*CollectedPoolMetrics, error) {
 	begin := time.Now()
 	var response []*PoolsMetrics //nolint prealloc
-	cookies, err := c.authenticate()
-	if err != nil {
-		c.Sugar.Error("Error during authentication.", err)
-		return nil, err
-	}
-
-	pools, err := c.listPools(cookies)
+	pools, err := c.listPools(ctx)
 	if err != nil {
 		c.Sugar.Error("Error getting pool list.", err)
 		return nil, err
@@ -502,9 +856,9 @@ func (c *Client) CollectPools(filter string) (*CollectedPoolMetrics, error) {
 	return &CollectedPoolMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
 }
 
-func (c *Client) listPools(cookies []*http.Cookie) ([]Pool, error) {
+func (c *Client) listPools(ctx context.Context) ([]Pool, error) {
 	// retrieve the system storage metrics ( already aggregated )
-	responseStorage, err := c.doRequest("GET", c.BaseURL+listPools, nil, cookies, nil)
+	responseStorage, err := c.doRequest(ctx, "GET", c.BaseURL+listPools, nil, nil)
 	if err != nil {
 		c.Sugar.Error("Error requesting pool metrics.", err)
 		return nil, err
@@ -523,17 +877,50 @@ func (c *Client) listPools(cookies []*http.Cookie) ([]Pool, error) {
 	return pools, nil
 }
 
-func (c *Client) authenticate() ([]*http.Cookie, error) {
+// session returns the current session generation, authenticating first when no session is open.
+func (c *Client) session(ctx context.Context) (int, error) {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if !c.loggedIn {
+		if err := c.authenticate(ctx); err != nil {
+			return 0, err
+		}
+		c.loggedIn = true
+		c.generation++
+	}
+	return c.generation, nil
+}
+
+// renewSession authenticates again, unless another call already renewed the expired session.
+func (c *Client) renewSession(ctx context.Context, expired int) error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if c.loggedIn && c.generation != expired {
+		return nil
+	}
+
+	c.loggedIn = false
+	if err := c.authenticate(ctx); err != nil {
+		return err
+	}
+	c.loggedIn = true
+	c.generation++
+	return nil
+}
+
+func (c *Client) authenticate(ctx context.Context) error {
 
 	payload := url.Values{}
 
 	payload.Set("j_username", c.Username)
 	payload.Set("j_password", c.Password)
 
-	req, err := http.NewRequest("POST", c.BaseURL+authenticate, strings.NewReader(payload.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+authenticate, strings.NewReader(payload.Encode()))
 	if err != nil {
 		c.Sugar.Error("Error creating request.", err)
-		return nil, err
+		return err
 	}
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
@@ -545,28 +932,99 @@ func (c *Client) authenticate() ([]*http.Cookie, error) {
 
 	if err != nil {
 		c.Sugar.Error("Error during authentication.", err)
-		return nil, err
+		return err
 	}
 
-	_, err = ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		c.Sugar.Error("Error reading response", err)
-		return nil, err
+		return err
 	}
 
-	return resp.Cookies(), nil
+	if resp.StatusCode >= http.StatusBadRequest {
+		return newAPIError(resp, body)
+	}
+
+	// a rejected login is redirected back to the login form, without session
+	if loginRedirect(resp) {
+		apiErr := newAPIError(resp, body)
+		apiErr.Message = "login rejected, redirected to " + resp.Header.Get("Location")
+		return apiErr
+	}
+
+	c.Sugar.Info("New IBM Spectrum session opened.")
+	return nil
 }
 
-// doRequest : method to call execute the http request
-func (c *Client) doRequest(method, url string, requestBody io.Reader, cookies []*http.Cookie, paramsMap map[string]string) ([]byte, error) { //nolint unparam
+// loginRedirect tells whether the response redirects to the login form
+func loginRedirect(resp *http.Response) bool {
+	if resp.StatusCode < http.StatusMultipleChoices || resp.StatusCode >= http.StatusBadRequest {
+		return false
+	}
+	location, err := resp.Location()
+	return err == nil && strings.Contains(strings.ToLower(location.Path), "login")
+}
 
-	req, err := http.NewRequest(method, url, requestBody)
+// Logout closes the IBM Spectrum session, if one is open
+func (c *Client) Logout(ctx context.Context) error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if !c.loggedIn {
+		return nil
+	}
+	c.loggedIn = false
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+logout, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return newAPIError(resp, body)
+	}
+
+	c.Sugar.Info("IBM Spectrum session closed.")
+	return nil
+}
+
+// doRequest : method to call execute the http request, opening or renewing the session when needed
+func (c *Client) doRequest(ctx context.Context, method, url string, requestBody []byte, paramsMap map[string]string) ([]byte, error) { //nolint unparam
+	session, err := c.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.sendWithRetries(ctx, method, url, requestBody, paramsMap)
+	if !IsUnauthorized(err) {
+		return body, err
+	}
+
+	c.Sugar.Info("IBM Spectrum session expired, authenticating again.")
+	err = c.renewSession(ctx, session)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, cookie := range cookies {
-		req.AddCookie(cookie)
+	return c.sendWithRetries(ctx, method, url, requestBody, paramsMap)
+}
+
+// send executes the http request within the current session
+func (c *Client) send(ctx context.Context, method, url string, requestBody []byte, paramsMap map[string]string) ([]byte, error) {
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(requestBody))
+	if err != nil {
+		return nil, err
 	}
 
 	if len(paramsMap) > 0 {
@@ -577,6 +1035,19 @@ func (c *Client) doRequest(method, url string, requestBody io.Reader, cookies []
 		req.URL.RawQuery = queryParams.Encode()
 	}
 
+	if err := c.waitRateLimit(ctx, url); err != nil {
+		return nil, err
+	}
+
+	if c.inFlight != nil {
+		select {
+		case c.inFlight <- struct{}{}:
+			defer func() { <-c.inFlight }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	resp, err := c.httpClient.Do(req)
 	if resp != nil && resp.Body != nil {
 		defer resp.Body.Close()
@@ -590,8 +1061,10 @@ func (c *Client) doRequest(method, url string, requestBody io.Reader, cookies []
 	if err != nil {
 		return nil, err
 	}
+
+	// redirects are not followed, IBM Spectrum only redirects to the login page when the session expired
 	if http.StatusOK != resp.StatusCode {
-		return nil, fmt.Errorf("%client", body)
+		return nil, newAPIError(resp, body)
 	}
 
 	return body, nil
@@ -1,12 +1,20 @@
 package spectrumservice
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
 
+	"github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
 )
 
 var (
@@ -36,3 +44,540 @@ var (
 		}
 	}))
 )
+
+func TestSessionReuse(t *testing.T) {
+	var logins, logouts, calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case authenticate:
+			session := atomic.AddInt32(&logins, 1)
+			http.SetCookie(w, &http.Cookie{Name: "LtpaToken2", Value: strconv.Itoa(int(session)), Path: "/"})
+		case logout:
+			atomic.AddInt32(&logouts, 1)
+		default:
+			// the first session expires after its first call
+			session, err := r.Cookie("LtpaToken2")
+			if err != nil || (session.Value == "1" && atomic.AddInt32(&calls, 1) > 1) {
+				http.Redirect(w, r, "/srm/login.jsp", http.StatusFound)
+				return
+			}
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.listPools(context.Background()); err != nil {
+			t.Fatalf("listPools() returned an error: %v", err)
+		}
+	}
+
+	if atomic.LoadInt32(&logins) != 2 {
+		t.Errorf("expected 2 logins, got %d", logins)
+	}
+
+	if err := client.Logout(context.Background()); err != nil {
+		t.Fatalf("Logout() returned an error: %v", err)
+	}
+
+	if err := client.Logout(context.Background()); err != nil {
+		t.Fatalf("second Logout() returned an error: %v", err)
+	}
+
+	if atomic.LoadInt32(&logouts) != 1 {
+		t.Errorf("expected 1 logout, got %d", logouts)
+	}
+}
+
+func TestRejectedLogin(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case authenticate:
+			// Spectrum Control redirects a rejected login to the login form
+			http.Redirect(w, r, "/srm/login.jsp?error=true", http.StatusFound)
+		default:
+			atomic.AddInt32(&calls, 1)
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "wrong", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	if _, err := client.listPools(context.Background()); !IsUnauthorized(err) {
+		t.Errorf("expected an authentication error, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("expected no call without session, got %d", calls)
+	}
+}
+
+func TestParallelStorageCollection(t *testing.T) {
+	var inFlight, maxInFlight, performanceRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"},{"id":"2","Name":"V2"},{"id":"3","Name":"V3"},`+
+				`{"id":"4","Name":"V4"},{"id":"5","Name":"V5"}]`)
+		case storageSystemPerformance:
+			atomic.AddInt32(&performanceRequests, 1)
+			fmt.Fprint(w, "[]")
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	// a storage system metric is configured, so that each storage system performance is requested
+	var config monitoring.MetricsConfig
+	config.Metrics.StorageSystems.Metrics = []monitoring.Metric{{MetricID: 803}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL, TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+	client.Concurrency = 4
+	client.SetMaxInFlightRequests(2)
+
+	collected, err := client.CollectStorageMetrics(context.Background(), ".*")
+	if err != nil {
+		t.Fatalf("CollectStorageMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 5 {
+		t.Fatalf("expected 5 storage systems, got %d", len(collected.Metrics))
+	}
+	for i, storageMetrics := range collected.Metrics {
+		if storageMetrics.Storage.ID != strconv.Itoa(i+1) {
+			t.Errorf("expected storage system %d at position %d, got %s", i+1, i, storageMetrics.Storage.ID)
+		}
+	}
+
+	if requests := atomic.LoadInt32(&performanceRequests); requests != 5 {
+		t.Errorf("expected 5 performance requests, got %d", requests)
+	}
+	if max := atomic.LoadInt32(&maxInFlight); max != 2 {
+		t.Errorf("expected 2 requests in flight at most, got %d", max)
+	}
+}
+
+func TestCacheCollectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != authenticate {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, cache.New(time.Minute, time.Minute), true,
+		"user", "password", server.URL, TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	// the collections failing together report their errors concurrently
+	filters, collectorsState := make(map[string]*string), make(map[string]*bool)
+	for _, collector := range []string{"storage", "volume", "host", "port", "node", "mdisk", "disk", "switch",
+		"switch_port", "fabric", "pool"} {
+		filter, enabled := ".*", true
+		filters[collector], collectorsState[collector] = &filter, &enabled
+	}
+
+	if err := client.CollectAndCacheMetrics(context.Background(), filters, collectorsState); err == nil {
+		t.Fatal("CollectAndCacheMetrics() expected an error")
+	}
+	if _, err := client.CollectFromPools(context.Background(), ".*"); err == nil {
+		t.Error("CollectFromPools() expected an error without cached pools")
+	}
+}
+
+func TestVolumeCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Volumes":
+			fmt.Fprint(w, `[{"id":"11","Name":"data","Pool":"pool1","Volume Unique ID":"600507680C80"},`+
+				`{"id":"12","Name":"data","Pool":"pool2","Volume Unique ID":"600507680C81"},`+
+				`{"id":"13","Name":"logs","Pool":"pool1","Volume Unique ID":"600507680C82"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Volumes/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":12,"deviceName":"data","metricId":803,"current":[{"x":1,"y":2.5}]},`+
+				`{"deviceId":11,"deviceName":"data","metricId":803,"current":[{"x":1,"y":1.5}]},`+
+				`{"deviceId":13,"deviceName":"logs","metricId":803,"current":[{"x":1,"y":3.5}]}]`)
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.Volumes.Metrics = []monitoring.Metric{{MetricID: 803}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectVolumeMetrics(context.Background(), "^DATA$")
+	if err != nil {
+		t.Fatalf("CollectVolumeMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 2 {
+		t.Fatalf("expected 2 volumes, got %d", len(collected.Metrics))
+	}
+	for _, volumeMetrics := range collected.Metrics {
+		volume := volumeMetrics.Volume
+		if len(volumeMetrics.VolumeMetrics) != 1 ||
+			strconv.Itoa(volumeMetrics.VolumeMetrics[0].DeviceID) != volume.ID {
+			t.Errorf("volume %s has the metrics %+v", volume.ID, volumeMetrics.VolumeMetrics)
+		}
+		if volumeMetrics.Storage.Name != "V1" {
+			t.Errorf("volume %s is on the storage system %s", volume.ID, volumeMetrics.Storage.Name)
+		}
+	}
+}
+
+func TestHostCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listHosts:
+			fmt.Fprint(w, `[{"id":"31","Name":"app01"},{"id":"32","Name":"db01"}]`)
+		case hostsPerformance:
+			if r.URL.Query().Get("ids") != "31" {
+				t.Errorf("performance requested for the hosts %s", r.URL.Query().Get("ids"))
+			}
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":31,"deviceName":"app01","metricId":803,"current":[{"x":1,"y":2.5}]}]`)
+		case "/srm/REST/api/v1/Hosts/31/Volumes":
+			fmt.Fprint(w, `[{"id":"11","Name":"data","Storage System":"V1","Volume Unique ID":"600507680C80"}]`)
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.Hosts = []monitoring.Metric{{MetricID: 803}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectHostMetrics(context.Background(), "^APP")
+	if err != nil {
+		t.Fatalf("CollectHostMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 1 {
+		t.Fatalf("expected 1 host, got %d", len(collected.Metrics))
+	}
+	hostMetrics := collected.Metrics[0]
+	if len(hostMetrics.HostMetrics) != 1 || hostMetrics.HostMetrics[0].DeviceID != 31 {
+		t.Errorf("host %s has the metrics %+v", hostMetrics.Host.ID, hostMetrics.HostMetrics)
+	}
+	if len(hostMetrics.Volumes) != 1 || hostMetrics.Volumes[0].VolumeUniqueID != "600507680C80" {
+		t.Errorf("host %s has the volumes %+v", hostMetrics.Host.ID, hostMetrics.Volumes)
+	}
+}
+
+func TestPortCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"},{"id":"2","Name":"DS1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Ports":
+			fmt.Fprint(w, `[{"id":"41","Name":"port1","WWPN":"500507680140ABCD"},{"id":"42","Name":"port2"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Ports/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":42,"deviceName":"port2","metricId":1029,"current":[{"x":1,"y":0.5}]}]`)
+		case "/srm/REST/api/v1/StorageSystems/2/Ports":
+			t.Error("ports listed for the storage system not matching the filter")
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.Ports = []monitoring.Metric{{MetricID: 1029}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectPortMetrics(context.Background(), "^V1$")
+	if err != nil {
+		t.Fatalf("CollectPortMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 2 {
+		t.Fatalf("expected 2 ports, got %d", len(collected.Metrics))
+	}
+	for _, portMetrics := range collected.Metrics {
+		port := portMetrics.Port
+		if port.ID == "42" && (len(portMetrics.PortMetrics) != 1 || portMetrics.PortMetrics[0].DeviceID != 42) ||
+			port.ID == "41" && len(portMetrics.PortMetrics) != 0 {
+			t.Errorf("port %s has the metrics %+v", port.ID, portMetrics.PortMetrics)
+		}
+		if portMetrics.Storage.Name != "V1" {
+			t.Errorf("port %s is on the storage system %s", port.ID, portMetrics.Storage.Name)
+		}
+	}
+}
+
+func TestNodeCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"},{"id":"2","Name":"DS1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Nodes":
+			fmt.Fprint(w, `[{"id":"51","Name":"node1","I/O Group":"io_grp0"},{"id":"52","Name":"node2"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/IOGroups":
+			fmt.Fprint(w, `[{"id":"61","Name":"io_grp0"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Nodes/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":51,"deviceName":"node1","metricId":1000,"current":[{"x":1,"y":97.5}]}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/IOGroups/Performance":
+			// the node only metrics are not requested for the I/O groups
+			if metrics := r.URL.Query().Get("metrics"); metrics != "1000," {
+				t.Errorf("expected the I/O group metrics 1000, got %s", metrics)
+			}
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":61,"deviceName":"io_grp0","metricId":1000,"current":[{"x":1,"y":95}]}]`)
+		case "/srm/REST/api/v1/StorageSystems/2/IOGroups":
+			t.Error("I/O groups listed for a storage system without nodes")
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.Nodes = []monitoring.Metric{{MetricID: 1000}, {MetricID: 2000}}
+	config.Metrics.IOGroups = []monitoring.Metric{{MetricID: 1000}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectNodeMetrics(context.Background(), ".*")
+	if err != nil {
+		t.Fatalf("CollectNodeMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 2 || len(collected.IOGroupMetrics) != 1 {
+		t.Fatalf("expected 2 nodes and 1 I/O group, got %d and %d", len(collected.Metrics),
+			len(collected.IOGroupMetrics))
+	}
+	if node := collected.Metrics[0]; len(node.NodeMetrics) != 1 || node.NodeMetrics[0].DeviceID != 51 {
+		t.Errorf("node %s has the metrics %+v", node.Node.ID, node.NodeMetrics)
+	}
+	if ioGroup := collected.IOGroupMetrics[0]; len(ioGroup.IOGroupMetrics) != 1 ||
+		ioGroup.IOGroupMetrics[0].DeviceID != 61 {
+		t.Errorf("I/O group %s has the metrics %+v", ioGroup.IOGroup.ID, ioGroup.IOGroupMetrics)
+	}
+}
+
+func TestNodeCollectionIOGroupError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Nodes":
+			fmt.Fprint(w, `[{"id":"51","Name":"node1","I/O Group":"io_grp0"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/IOGroups":
+			fmt.Fprint(w, `[{"id":"61","Name":"io_grp0"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Nodes/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":51,"deviceName":"node1","metricId":1000,"current":[{"x":1,"y":97.5}]}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/IOGroups/Performance":
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.Nodes = []monitoring.Metric{{MetricID: 1000}}
+	config.Metrics.IOGroups = []monitoring.Metric{{MetricID: 1000}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectNodeMetrics(context.Background(), ".*")
+	if err != nil {
+		t.Fatalf("CollectNodeMetrics() returned an error: %v", err)
+	}
+
+	// the nodes are kept with their metrics, the I/O groups without metrics
+	if len(collected.Metrics) != 1 || len(collected.Metrics[0].NodeMetrics) != 1 {
+		t.Fatalf("expected node1 with its metrics, got %+v", collected.Metrics)
+	}
+	if len(collected.IOGroupMetrics) != 1 || len(collected.IOGroupMetrics[0].IOGroupMetrics) != 0 {
+		t.Errorf("expected io_grp0 without metrics, got %+v", collected.IOGroupMetrics)
+	}
+}
+
+func TestManagedDiskCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"},{"id":"2","Name":"DS1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/ManagedDisks":
+			fmt.Fprint(w, `[{"id":"71","Name":"mdisk0","Pool":"pool1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/ManagedDisks/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":71,"deviceName":"mdisk0","metricId":822,"current":[{"x":1,"y":0.8}]}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Ranks":
+			t.Error("ranks listed for a storage system with managed disks")
+		case "/srm/REST/api/v1/StorageSystems/2/Ranks":
+			fmt.Fprint(w, `[{"id":"72","Name":"R1","Pool":"P0"}]`)
+		case "/srm/REST/api/v1/StorageSystems/2/Ranks/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":72,"deviceName":"R1","metricId":822,"current":[{"x":1,"y":1.2}]}]`)
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.ManagedDisks.Metrics = []monitoring.Metric{{MetricID: 822}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectManagedDiskMetrics(context.Background(), ".*")
+	if err != nil {
+		t.Fatalf("CollectManagedDiskMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 2 {
+		t.Fatalf("expected 2 managed disks, got %d", len(collected.Metrics))
+	}
+	for i, expectedType := range []string{"managedDisk", "rank"} {
+		managedDiskMetrics := collected.Metrics[i]
+		if managedDiskMetrics.Type != expectedType || len(managedDiskMetrics.ManagedDiskMetrics) != 1 ||
+			strconv.Itoa(managedDiskMetrics.ManagedDiskMetrics[0].DeviceID) != managedDiskMetrics.ManagedDisk.ID {
+			t.Errorf("unexpected %s %+v", expectedType, managedDiskMetrics)
+		}
+	}
+}
+
+func TestDiskCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listStorageSystems:
+			fmt.Fprint(w, `[{"id":"1","Name":"V1"},{"id":"2","Name":"V2"},{"id":"3","Name":"DS1"}]`)
+		case "/srm/REST/api/v1/StorageSystems/1/Disks":
+			fmt.Fprint(w, `[{"id":"61","Name":"disk1","Capacity":"1.75 TiB"},{"id":"62","Name":"disk2"}]`)
+		case "/srm/REST/api/v1/StorageSystems/2/Disks":
+			fmt.Fprint(w, `[{"id":"63","Name":"disk3"}]`)
+		case "/srm/REST/api/v1/StorageSystems/3/Disks":
+			t.Error("disks listed for the storage system not matching the filter")
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+	client.Concurrency = 2
+
+	collected, err := client.CollectDiskMetrics(context.Background(), "^V")
+	if err != nil {
+		t.Fatalf("CollectDiskMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 3 {
+		t.Fatalf("expected 3 disks, got %d", len(collected.Metrics))
+	}
+	// the disks are kept in the order of the storage systems
+	for i, expected := range []struct{ disk, storage string }{{"disk1", "V1"}, {"disk2", "V1"}, {"disk3", "V2"}} {
+		diskMetrics := collected.Metrics[i]
+		if diskMetrics.Disk.Name != expected.disk || diskMetrics.Storage.Name != expected.storage {
+			t.Errorf("expected %s on %s at position %d, got %s on %s", expected.disk, expected.storage, i,
+				diskMetrics.Disk.Name, diskMetrics.Storage.Name)
+		}
+	}
+	if capacity := collected.Metrics[0].Disk.Capacity; capacity != "1.75 TiB" {
+		t.Errorf("expected the capacity 1.75 TiB of disk1, got %s", capacity)
+	}
+}
+
+func TestSwitchPortCollection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case listSwitches:
+			fmt.Fprint(w, `[{"id":"5","Name":"sw1","Fabric":"fabricA"},{"id":"6","Name":"core1"}]`)
+		case "/srm/REST/api/v1/Switches/5/Ports":
+			fmt.Fprint(w, `[{"id":"91","Name":"port0","Port Index":"0"},{"id":"92","Name":"port1","Port Index":"1"}]`)
+		case "/srm/REST/api/v1/Switches/5/Ports/Performance":
+			fmt.Fprint(w, `[{"metricDetails":{}},`+
+				`{"deviceId":92,"deviceName":"port1","metricId":880,"current":[{"x":1,"y":1.5}]}]`)
+		case "/srm/REST/api/v1/Switches/6/Ports":
+			t.Error("ports listed for the switch not matching the filter")
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	var config monitoring.MetricsConfig
+	config.Metrics.SwitchPorts = []monitoring.Metric{{MetricID: 880}}
+	client, err := NewClient(logger.Sugar(), config, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	collected, err := client.CollectSwitchPortMetrics(context.Background(), "^SW")
+	if err != nil {
+		t.Fatalf("CollectSwitchPortMetrics() returned an error: %v", err)
+	}
+
+	if len(collected.Metrics) != 2 {
+		t.Fatalf("expected 2 ports, got %d", len(collected.Metrics))
+	}
+	if port := collected.Metrics[1]; port.Switch.Fabric != "fabricA" || len(port.SwitchPortMetrics) != 1 ||
+		port.SwitchPortMetrics[0].DeviceID != 92 {
+		t.Errorf("unexpected port %+v", port)
+	}
+}
@@ -0,0 +1,192 @@
+package spectrumservice
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StorageSystems lists all the storage systems monitored by IBM Spectrum
+func (c *Client) StorageSystems(ctx context.Context) ([]StorageSystem, error) {
+	return c.listStorageSystems(ctx, "")
+}
+
+// Switches lists all the switches monitored by IBM Spectrum
+func (c *Client) Switches(ctx context.Context) ([]Switch, error) {
+	return c.listSwitches(ctx)
+}
+
+// StorageSystemCatalog returns the metrics available for a storage system, by metric ID
+func (c *Client) StorageSystemCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	paramsMap := catalogParams()
+	paramsMap["ids"] = storageSystemID
+
+	return c.metricCatalog(ctx, c.BaseURL+storageSystemPerformance, paramsMap)
+}
+
+// VolumeCatalog returns the metrics available for the volumes of a storage system, by metric ID.
+// It is empty when the storage system has no volume.
+func (c *Client) VolumeCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	volumes, err := c.listVolumes(ctx, storageSystemID)
+	if err != nil || len(volumes) == 0 {
+		return nil, err
+	}
+
+	// the catalog is the same for all the volumes, asking for one keeps the response small
+	ids := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		ids = append(ids, volume.ID)
+	}
+	sort.Strings(ids)
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = ids[0]
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+volumesPerformance, "{storageSystemID}",
+		storageSystemID, -1), paramsMap)
+}
+
+// PortCatalog returns the metrics available for the ports of a storage system, by metric ID.
+// It is empty when the storage system has no port.
+func (c *Client) PortCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	var ports []Port
+	err := c.listStorageResources(ctx, listPorts, StorageSystem{ID: storageSystemID, Name: storageSystemID}, &ports)
+	if err != nil || len(ports) == 0 {
+		return nil, err
+	}
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = ports[0].ID
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+portsPerformance, "{storageSystemID}",
+		storageSystemID, -1), paramsMap)
+}
+
+// NodeCatalog returns the metrics available for the nodes of a storage system, by metric ID.
+// It is empty when the storage system has no node.
+func (c *Client) NodeCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	var nodes []Node
+	err := c.listStorageResources(ctx, listNodes, StorageSystem{ID: storageSystemID, Name: storageSystemID}, &nodes)
+	if err != nil || len(nodes) == 0 {
+		return nil, err
+	}
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = nodes[0].ID
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+nodesPerformance, "{storageSystemID}",
+		storageSystemID, -1), paramsMap)
+}
+
+// IOGroupCatalog returns the metrics available for the I/O groups of a storage system, by metric ID.
+// It is empty when the storage system has no I/O group.
+func (c *Client) IOGroupCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	var ioGroups []IOGroup
+	err := c.listStorageResources(ctx, listIOGroups, StorageSystem{ID: storageSystemID, Name: storageSystemID},
+		&ioGroups)
+	if err != nil || len(ioGroups) == 0 {
+		return nil, err
+	}
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = ioGroups[0].ID
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+ioGroupsPerformance, "{storageSystemID}",
+		storageSystemID, -1), paramsMap)
+}
+
+// ManagedDiskCatalog returns the metrics available for the managed disks of a storage system, or its ranks
+// for the storage systems without managed disks, by metric ID. It is empty when the storage system has neither.
+func (c *Client) ManagedDiskCatalog(ctx context.Context, storageSystemID string) (map[int]MetricDetail, error) {
+	storage := StorageSystem{ID: storageSystemID, Name: storageSystemID}
+	performance := managedDisksPerformance
+
+	var managedDisks []ManagedDisk
+	err := c.listStorageResources(ctx, listManagedDisks, storage, &managedDisks)
+	if err == nil && len(managedDisks) == 0 {
+		performance = ranksPerformance
+		err = c.listStorageResources(ctx, listRanks, storage, &managedDisks)
+	}
+	if err != nil || len(managedDisks) == 0 {
+		return nil, err
+	}
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = managedDisks[0].ID
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+performance, "{storageSystemID}",
+		storageSystemID, -1), paramsMap)
+}
+
+// SwitchCatalog returns the metrics available for a switch, by metric ID
+func (c *Client) SwitchCatalog(ctx context.Context, switchID string) (map[int]MetricDetail, error) {
+	paramsMap := catalogParams()
+	paramsMap["ids"] = switchID
+
+	return c.metricCatalog(ctx, c.BaseURL+switchPerformance, paramsMap)
+}
+
+// SwitchPortCatalog returns the metrics available for the ports of a switch, by metric ID.
+// It is empty when the switch has no port.
+func (c *Client) SwitchPortCatalog(ctx context.Context, switchID string) (map[int]MetricDetail, error) {
+	var ports []SwitchPort
+	err := c.listResources(ctx, strings.Replace(listSwitchPorts, "{switchID}", switchID, -1), "Switch "+switchID,
+		&ports)
+	if err != nil || len(ports) == 0 {
+		return nil, err
+	}
+
+	paramsMap := catalogParams()
+	paramsMap["ids"] = ports[0].ID
+
+	return c.metricCatalog(ctx, strings.Replace(c.BaseURL+switchPortsPerformance, "{switchID}", switchID, -1),
+		paramsMap)
+}
+
+// catalogParams returns the parameters of a performance request without selected metrics,
+// IBM Spectrum then describes all the metrics available for the resource
+func catalogParams() map[string]string {
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
+
+	return map[string]string{
+		"startTime":   strconv.FormatInt(timeInMillis, 10),
+		"granularity": "sample",
+	}
+}
+
+// metricCatalog requests a performance endpoint and decodes the metric details of its first element
+func (c *Client) metricCatalog(ctx context.Context, url string, paramsMap map[string]string) (map[int]MetricDetail, error) {
+	response, err := c.doRequest(ctx, "GET", url, nil, paramsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var objArray []*json.RawMessage
+	if err := json.Unmarshal(response, &objArray); err != nil {
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return nil, err
+	}
+
+	catalog := make(map[int]MetricDetail)
+	if len(objArray) == 0 || objArray[0] == nil {
+		return catalog, nil
+	}
+
+	var details MetricDetails
+	if err := json.Unmarshal(*objArray[0], &details); err != nil {
+		return nil, err
+	}
+
+	for key, detail := range details.Metrics {
+		metricID, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		catalog[metricID] = detail
+	}
+
+	return catalog, nil
+}
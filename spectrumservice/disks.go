@@ -0,0 +1,42 @@
+package spectrumservice
+
+import (
+	"context"
+	"time"
+)
+
+// CollectDiskMetrics collects the physical drives of the storage systems matching the filter
+func (c *Client) CollectDiskMetrics(ctx context.Context, filter string) (*CollectedDiskMetrics, error) {
+	begin := time.Now()
+	storages, err := c.listStorageSystems(ctx, filter)
+	if err != nil {
+		c.Sugar.Error("Error getting storage systems list.", err)
+		return nil, err
+	}
+
+	results := make([][]*DiskMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		var disks []Disk
+		if err := c.listStorageResources(ctx, listDisks, storages[i], &disks); err != nil {
+			return err
+		}
+
+		for _, disk := range disks {
+			results[i] = append(results[i], &DiskMetrics{Storage: storages[i], Disk: disk})
+		}
+		c.Sugar.Infof("Disks retrieved for Storage System %s : %d", storages[i].Name, len(disks))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*DiskMetrics //nolint prealloc
+	for _, diskMetrics := range results {
+		response = append(response, diskMetrics...)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedDiskMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
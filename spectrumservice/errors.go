@@ -0,0 +1,115 @@
+package spectrumservice
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// maxErrorBody is the maximum size of the raw body kept in an APIError
+const maxErrorBody = 512
+
+// APIError is returned when IBM Spectrum answers a request with an unexpected status
+type APIError struct {
+	StatusCode int
+	Method     string
+	// Path is the URL path of the request, including the resource ID
+	Path string
+	// Message is the error message parsed from the IBM Spectrum JSON body, if any
+	Message string
+	// Body is the raw response body, truncated
+	Body string
+	// RetryAfter is the Retry-After header returned by IBM Spectrum
+	RetryAfter string
+}
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.Body
+	}
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), message)
+}
+
+// newAPIError builds the error from the response of a failed request
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		Path:       resp.Request.URL.Path,
+		RetryAfter: resp.Header.Get("Retry-After"),
+	}
+
+	var errorBody struct {
+		Message      string `json:"message"`
+		ErrorMessage string `json:"errorMessage"`
+		Error        string `json:"error"`
+	}
+	if json.Unmarshal(body, &errorBody) == nil {
+		for _, message := range []string{errorBody.Message, errorBody.ErrorMessage, errorBody.Error} {
+			if message != "" {
+				apiErr.Message = message
+				break
+			}
+		}
+	}
+
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	apiErr.Body = string(body)
+
+	return apiErr
+}
+
+// sessionExpired tells whether IBM Spectrum denied the access or redirected to the login page
+func (e *APIError) sessionExpired() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+		(e.StatusCode >= http.StatusMultipleChoices && e.StatusCode < http.StatusBadRequest)
+}
+
+// IsUnauthorized tells whether the error is an authentication or authorization failure
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.sessionExpired()
+}
+
+// IsNotFound tells whether the requested resource does not exist on IBM Spectrum
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsRetryable tells whether the error is transient, e.g. IBM Spectrum is busy or the connection was reset
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return retryableStatusCodes[apiErr.StatusCode]
+	}
+
+	// the server certificate is rejected again by every attempt
+	if certificateRejected(err) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// certificateRejected tells whether the TLS handshake failed on the verification of the server certificate
+func certificateRejected(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) ||
+		errors.Is(err, errFingerprintMismatch)
+}
@@ -0,0 +1,85 @@
+package spectrumservice
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+)
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == authenticate {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":"BPCUI0001E","message":"The storage system 42 was not found."}`+strings.Repeat(" ", 1024))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+
+	_, err = client.listVolumes(context.Background(), "42")
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+
+	if apiErr.StatusCode != http.StatusNotFound || apiErr.Method != "GET" ||
+		apiErr.Path != "/srm/REST/api/v1/StorageSystems/42/Volumes" {
+		t.Errorf("unexpected request in the error: %d %s %s", apiErr.StatusCode, apiErr.Method, apiErr.Path)
+	}
+	if apiErr.Message != "The storage system 42 was not found." {
+		t.Errorf("unexpected message %q", apiErr.Message)
+	}
+	if len(apiErr.Body) != maxErrorBody {
+		t.Errorf("expected the body truncated to %d bytes, got %d", maxErrorBody, len(apiErr.Body))
+	}
+
+	if !IsNotFound(err) || IsUnauthorized(err) || IsRetryable(err) {
+		t.Errorf("unexpected kind of error: %v", err)
+	}
+	if !IsNotFound(fmt.Errorf("listing volumes: %w", err)) {
+		t.Error("IsNotFound() expected to unwrap the error")
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		err          error
+		unauthorized bool
+		retryable    bool
+	}{
+		{&APIError{StatusCode: http.StatusUnauthorized}, true, false},
+		{&APIError{StatusCode: http.StatusFound}, true, false},
+		{&APIError{StatusCode: http.StatusServiceUnavailable}, false, true},
+		{&APIError{StatusCode: http.StatusBadRequest}, false, false},
+		{context.DeadlineExceeded, false, false},
+		{&url.Error{Op: "Get", URL: "https://spectrum", Err: errors.New("connection reset by peer")}, false, true},
+		// the rejected server certificates are not retried
+		{&url.Error{Op: "Get", URL: "https://spectrum", Err: x509.UnknownAuthorityError{}}, false, false},
+		{&url.Error{Op: "Get", URL: "https://spectrum", Err: x509.CertificateInvalidError{Reason: x509.Expired}},
+			false, false},
+		{&url.Error{Op: "Get", URL: "https://spectrum", Err: x509.HostnameError{Host: "spectrum"}}, false, false},
+		{&url.Error{Op: "Get", URL: "https://spectrum", Err: fmt.Errorf("%w: 1234", errFingerprintMismatch)},
+			false, false},
+	}
+
+	for _, test := range tests {
+		if IsUnauthorized(test.err) != test.unauthorized || IsRetryable(test.err) != test.retryable {
+			t.Errorf("%v: expected unauthorized=%v retryable=%v", test.err, test.unauthorized, test.retryable)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package spectrumservice
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CollectFabrics collects the fabrics matching the filter and their member switches
+func (c *Client) CollectFabrics(ctx context.Context, filter string) (*CollectedFabricMetrics, error) {
+	begin := time.Now()
+	response, err := c.doRequest(ctx, "GET", c.BaseURL+listFabrics, nil, nil)
+	if err != nil {
+		c.Sugar.Error("Error listing fabrics.", err)
+		return nil, err
+	}
+
+	var fabrics []Fabric
+	err = json.Unmarshal(response, &fabrics)
+	if err != nil {
+		c.Sugar.Error("Error reading fabrics.", err)
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return nil, err
+	}
+
+	c.Sugar.Infof("Number of Fabrics retrieved: %d", len(fabrics))
+
+	switches, err := c.listSwitches(ctx)
+	if err != nil {
+		c.Sugar.Error("Error getting switches list.", err)
+		return nil, err
+	}
+
+	var metrics []*FabricMetrics //nolint prealloc
+	for _, fabric := range fabrics {
+		matched, err := regexp.MatchString(filter, strings.ToUpper(fabric.Name))
+		if err != nil {
+			c.Sugar.Error("Error matching regex.", err)
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		fabricMetrics := &FabricMetrics{Fabric: fabric}
+		for _, s := range switches {
+			if strings.TrimSpace(s.Fabric) == strings.TrimSpace(fabric.Name) {
+				fabricMetrics.Switches = append(fabricMetrics.Switches, s)
+			}
+		}
+		metrics = append(metrics, fabricMetrics)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedFabricMetrics{Metrics: metrics, CollectionDuration: duration.Seconds()}, nil
+}
@@ -0,0 +1,135 @@
+package spectrumservice
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CollectHostMetrics collects the performance metrics and the volume mappings of the hosts matching the filter
+func (c *Client) CollectHostMetrics(ctx context.Context, filter string) (*CollectedHostMetrics, error) {
+	begin := time.Now()
+	hosts, err := c.listHosts(ctx, filter)
+	if err != nil {
+		c.Sugar.Error("Error getting hosts list.", err)
+		return nil, err
+	}
+
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
+	metricIDs := joinMetricIDs(c.Config.Metrics.Hosts)
+
+	results := make([]*HostMetrics, len(hosts))
+	err = c.forEach(ctx, len(hosts), func(ctx context.Context, i int) error {
+		hostMetrics, err := c.collectHost(ctx, hosts[i], timeInMillis, metricIDs)
+		results[i] = hostMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*HostMetrics //nolint prealloc
+	for _, hostMetrics := range results {
+		if hostMetrics != nil {
+			response = append(response, hostMetrics)
+		}
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedHostMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectHost collects the metrics and the volumes of a host
+func (c *Client) collectHost(ctx context.Context, host Host, startTime int64, metricIDs string) (*HostMetrics, error) {
+	hostMetrics := &HostMetrics{Host: host}
+
+	if metricIDs != "" {
+		paramsMap := make(map[string]string)
+		paramsMap["startTime"] = strconv.FormatInt(startTime, 10)
+		paramsMap["granularity"] = "sample"
+		paramsMap["metrics"] = metricIDs
+		paramsMap["ids"] = host.ID
+
+		response, err := c.doRequest(ctx, "GET", c.BaseURL+hostsPerformance, nil, paramsMap)
+		if IsNotFound(err) {
+			c.Sugar.Warnf("Host %s not found, it may have been removed.", host.Name)
+			return nil, err
+		}
+		if err != nil {
+			c.Sugar.Errorf("Error collecting the metrics of host %s. %v", host.Name, err)
+			return nil, err
+		}
+
+		hostMetrics.HostMetrics, err = c.decodePerformance(response, paramsMap)
+		if err != nil {
+			c.Sugar.Error("Error reading host metrics response.", err)
+			c.Sugar.Errorf("Response received: %s", string(response))
+			return nil, err
+		}
+	}
+
+	response, err := c.doRequest(ctx, "GET", strings.Replace(c.BaseURL+listHostVolumes, "{hostID}", host.ID, -1),
+		nil, nil)
+	if err != nil {
+		c.Sugar.Errorf("Error listing the volumes of host %s. %v", host.Name, err)
+		return nil, err
+	}
+
+	err = json.Unmarshal(response, &hostMetrics.Volumes)
+	if err != nil {
+		c.Sugar.Error("Error reading host volumes response.", err)
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return nil, err
+	}
+
+	return hostMetrics, nil
+}
+
+// Hosts lists all the hosts known by IBM Spectrum
+func (c *Client) Hosts(ctx context.Context) ([]Host, error) {
+	return c.listHosts(ctx, ".*")
+}
+
+// HostCatalog returns the metrics available for a host, by metric ID
+func (c *Client) HostCatalog(ctx context.Context, hostID string) (map[int]MetricDetail, error) {
+	paramsMap := catalogParams()
+	paramsMap["ids"] = hostID
+
+	return c.metricCatalog(ctx, c.BaseURL+hostsPerformance, paramsMap)
+}
+
+func (c *Client) listHosts(ctx context.Context, regex string) ([]Host, error) {
+	response, err := c.doRequest(ctx, "GET", c.BaseURL+listHosts, nil, nil)
+	if err != nil {
+		c.Sugar.Error("Error listing hosts.", err)
+		return nil, err
+	}
+
+	var hosts []Host
+	err = json.Unmarshal(response, &hosts)
+	if err != nil {
+		c.Sugar.Error("Error reading hosts.", err)
+		c.Sugar.Errorf("Response received: %s", string(response))
+		return nil, err
+	}
+
+	c.Sugar.Infof("Number of Hosts retrieved: %d", len(hosts))
+
+	var selected []Host
+	for _, host := range hosts {
+		matched, err := regexp.MatchString(regex, strings.ToUpper(host.Name))
+		if err != nil {
+			c.Sugar.Error("Error matching regex.", err)
+			return nil, err
+		}
+		if matched {
+			selected = append(selected, host)
+		}
+	}
+
+	return selected, nil
+}
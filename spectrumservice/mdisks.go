@@ -0,0 +1,75 @@
+package spectrumservice
+
+import (
+	"context"
+	"time"
+)
+
+// CollectManagedDiskMetrics collects the managed disks, or the ranks of the DS8000, and their performance metrics
+// of the storage systems matching the filter
+func (c *Client) CollectManagedDiskMetrics(ctx context.Context, filter string) (*CollectedManagedDiskMetrics, error) {
+	begin := time.Now()
+	storages, err := c.listStorageSystems(ctx, filter)
+	if err != nil {
+		c.Sugar.Error("Error getting storage systems list.", err)
+		return nil, err
+	}
+
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
+	metricIDs := joinMetricIDs(c.Config.Metrics.ManagedDisks.Metrics)
+
+	results := make([][]*ManagedDiskMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		managedDiskMetrics, err := c.collectStorageManagedDisks(ctx, storages[i], timeInMillis, metricIDs)
+		results[i] = managedDiskMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*ManagedDiskMetrics //nolint prealloc
+	for _, managedDiskMetrics := range results {
+		response = append(response, managedDiskMetrics...)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedManagedDiskMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectStorageManagedDisks collects the managed disks of a storage system and their metrics.
+// The ranks are collected for the storage systems without managed disks, e.g. DS8000.
+func (c *Client) collectStorageManagedDisks(ctx context.Context, storage StorageSystem, startTime int64,
+	metricIDs string) ([]*ManagedDiskMetrics, error) {
+	managedDiskType, performance := "managedDisk", managedDisksPerformance
+
+	var managedDisks []ManagedDisk
+	if err := c.listStorageResources(ctx, listManagedDisks, storage, &managedDisks); err != nil {
+		return nil, err
+	}
+	if len(managedDisks) == 0 {
+		managedDiskType, performance = "rank", ranksPerformance
+		if err := c.listStorageResources(ctx, listRanks, storage, &managedDisks); err != nil {
+			return nil, err
+		}
+	}
+	if len(managedDisks) == 0 {
+		return nil, nil
+	}
+
+	metrics, err := c.storageResourcesMetrics(ctx, performance, storage, startTime, metricIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*ManagedDiskMetrics, 0, len(managedDisks))
+	for _, managedDisk := range managedDisks {
+		response = append(response, &ManagedDiskMetrics{Storage: storage, Type: managedDiskType,
+			ManagedDisk: managedDisk, ManagedDiskMetrics: metrics[managedDisk.ID]})
+	}
+
+	c.Sugar.Infof("Managed disks retrieved for Storage System %s : %d", storage.Name, len(managedDisks))
+
+	return response, nil
+}
@@ -0,0 +1,28 @@
+package spectrumservice
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "storage"
+
+var (
+	requestRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spectrum_client",
+		Name:      "request_retries_total",
+		Help:      "ibm_spectrum_exporter: Number of IBM Spectrum requests retried, by reason.",
+	}, []string{"spectrum_server", "reason"})
+
+	rateLimiterWait = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spectrum_client",
+		Name:      "rate_limiter_wait_seconds_total",
+		Help:      "ibm_spectrum_exporter: Time spent waiting for the rate limiter, by endpoint family.",
+	}, []string{"spectrum_server", "family"})
+)
+
+// Metrics returns the internal metrics of the IBM Spectrum client
+func Metrics() []prometheus.Collector {
+	return []prometheus.Collector{requestRetries, rateLimiterWait}
+}
@@ -0,0 +1,95 @@
+package spectrumservice
+
+import (
+	"context"
+	"time"
+)
+
+// CollectNodeMetrics collects the nodes and I/O groups and their performance metrics of the storage systems
+// matching the filter
+func (c *Client) CollectNodeMetrics(ctx context.Context, filter string) (*CollectedNodeMetrics, error) {
+	begin := time.Now()
+	storages, err := c.listStorageSystems(ctx, filter)
+	if err != nil {
+		c.Sugar.Error("Error getting storage systems list.", err)
+		return nil, err
+	}
+
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
+	nodeMetricIDs := joinMetricIDs(c.Config.Metrics.Nodes)
+	ioGroupMetricIDs := joinMetricIDs(c.Config.Metrics.IOGroups)
+
+	results := make([]*CollectedNodeMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		nodeMetrics, err := c.collectStorageNodes(ctx, storages[i], timeInMillis, nodeMetricIDs,
+			ioGroupMetricIDs)
+		results[i] = nodeMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CollectedNodeMetrics{}
+	for _, nodeMetrics := range results {
+		if nodeMetrics != nil {
+			response.Metrics = append(response.Metrics, nodeMetrics.Metrics...)
+			response.IOGroupMetrics = append(response.IOGroupMetrics, nodeMetrics.IOGroupMetrics...)
+		}
+	}
+
+	duration := time.Since(begin)
+	response.CollectionDuration = duration.Seconds()
+
+	return response, nil
+}
+
+// collectStorageNodes collects the nodes and I/O groups of a storage system and their metrics.
+// The storage systems without nodes, e.g. DS8000, are skipped. The nodes are kept when the I/O group metrics fail.
+func (c *Client) collectStorageNodes(ctx context.Context, storage StorageSystem, startTime int64,
+	nodeMetricIDs, ioGroupMetricIDs string) (*CollectedNodeMetrics, error) {
+	var nodes []Node
+	if err := c.listStorageResources(ctx, listNodes, storage, &nodes); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	var ioGroups []IOGroup
+	if err := c.listStorageResources(ctx, listIOGroups, storage, &ioGroups); err != nil {
+		return nil, err
+	}
+
+	metrics, err := c.storageResourcesMetrics(ctx, nodesPerformance, storage, startTime, nodeMetricIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CollectedNodeMetrics{}
+	for _, node := range nodes {
+		response.Metrics = append(response.Metrics,
+			&NodeMetrics{Storage: storage, Node: node, NodeMetrics: metrics[node.ID]})
+	}
+
+	var ioGroupMetrics map[string][]MetricValue
+	if len(ioGroups) > 0 {
+		ioGroupMetrics, err = c.storageResourcesMetrics(ctx, ioGroupsPerformance, storage, startTime,
+			ioGroupMetricIDs)
+		if err != nil {
+			c.Sugar.Errorf("Error collecting the I/O group metrics of storage system %s, keeping its nodes. %v",
+				storage.Name, err)
+		}
+	}
+
+	for _, ioGroup := range ioGroups {
+		response.IOGroupMetrics = append(response.IOGroupMetrics,
+			&IOGroupMetrics{Storage: storage, IOGroup: ioGroup, IOGroupMetrics: ioGroupMetrics[ioGroup.ID]})
+	}
+
+	c.Sugar.Infof("Nodes retrieved for Storage System %s : %d, I/O groups : %d", storage.Name, len(nodes),
+		len(ioGroups))
+
+	// an authentication failure still stops the collection
+	return response, err
+}
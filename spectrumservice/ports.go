@@ -0,0 +1,61 @@
+package spectrumservice
+
+import (
+	"context"
+	"time"
+)
+
+// CollectPortMetrics collects the ports and their performance metrics of the storage systems matching the filter
+func (c *Client) CollectPortMetrics(ctx context.Context, filter string) (*CollectedPortMetrics, error) {
+	begin := time.Now()
+	storages, err := c.listStorageSystems(ctx, filter)
+	if err != nil {
+		c.Sugar.Error("Error getting storage systems list.", err)
+		return nil, err
+	}
+
+	timeInMillis := time.Now().Add(time.Duration(-10)*time.Minute).UnixNano() / 1000000
+	metricIDs := joinMetricIDs(c.Config.Metrics.Ports)
+
+	results := make([][]*PortMetrics, len(storages))
+	err = c.forEach(ctx, len(storages), func(ctx context.Context, i int) error {
+		portMetrics, err := c.collectStoragePorts(ctx, storages[i], timeInMillis, metricIDs)
+		results[i] = portMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*PortMetrics //nolint prealloc
+	for _, portMetrics := range results {
+		response = append(response, portMetrics...)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedPortMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectStoragePorts collects the ports of a storage system and their metrics
+func (c *Client) collectStoragePorts(ctx context.Context, storage StorageSystem, startTime int64,
+	metricIDs string) ([]*PortMetrics, error) {
+	var ports []Port
+	if err := c.listStorageResources(ctx, listPorts, storage, &ports); err != nil {
+		return nil, err
+	}
+
+	metrics, err := c.storageResourcesMetrics(ctx, portsPerformance, storage, startTime, metricIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*PortMetrics, 0, len(ports))
+	for _, port := range ports {
+		response = append(response, &PortMetrics{Storage: storage, Port: port, PortMetrics: metrics[port.ID]})
+	}
+
+	c.Sugar.Infof("Ports retrieved for Storage System %s : %d", storage.Name, len(ports))
+
+	return response, nil
+}
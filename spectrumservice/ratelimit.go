@@ -0,0 +1,78 @@
+package spectrumservice
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// endpoint families, limited separately
+const (
+	// AllEndpoints is the family of all the requests
+	AllEndpoints = "all"
+	// PerformanceEndpoints is the family of the requests on the Performance resources
+	PerformanceEndpoints = "performance"
+	// InventoryEndpoints is the family of the other requests, listing the resources
+	InventoryEndpoints = "inventory"
+)
+
+// RateLimit configures a token bucket limiting the requests sent to IBM Spectrum
+type RateLimit struct {
+	// RequestsPerSecond is the rate of the requests, 0 disables the limit
+	RequestsPerSecond float64
+	// Burst is the number of requests sent at once before being limited
+	Burst int
+}
+
+// SetRateLimit limits the requests of the given endpoint family. A request waits for the limit of all the
+// endpoints, then for the limit of its family. It must be called before the first collection.
+func (c *Client) SetRateLimit(family string, limit RateLimit) {
+	if c.limiters == nil {
+		c.limiters = make(map[string]*rate.Limiter)
+	}
+	if limit.RequestsPerSecond <= 0 {
+		delete(c.limiters, family)
+		return
+	}
+
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	c.limiters[family] = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+}
+
+// endpointFamily returns the family of the requested url
+func endpointFamily(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err == nil && strings.HasSuffix(u.Path, "/Performance") {
+		return PerformanceEndpoints
+	}
+	return InventoryEndpoints
+}
+
+// waitRateLimit waits until the request is allowed by the rate limits
+func (c *Client) waitRateLimit(ctx context.Context, requestURL string) error {
+	if len(c.limiters) == 0 {
+		return nil
+	}
+
+	family := endpointFamily(requestURL)
+	for _, key := range []string{AllEndpoints, family} {
+		limiter, found := c.limiters[key]
+		if !found {
+			continue
+		}
+
+		begin := time.Now()
+		err := limiter.Wait(ctx)
+		rateLimiterWait.WithLabelValues(c.Name, family).Add(time.Since(begin).Seconds())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
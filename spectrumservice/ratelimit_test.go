@@ -0,0 +1,54 @@
+package spectrumservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+)
+
+func TestRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+	client.Name = "ratelimit"
+	client.SetRateLimit(PerformanceEndpoints, RateLimit{RequestsPerSecond: 20, Burst: 1})
+
+	// the inventory requests are not limited
+	begin := time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.listPools(context.Background()); err != nil {
+			t.Fatalf("listPools() returned an error: %v", err)
+		}
+	}
+	if elapsed := time.Since(begin); elapsed > 100*time.Millisecond {
+		t.Errorf("inventory requests took %s, expected no limit", elapsed)
+	}
+
+	begin = time.Now()
+	for i := 0; i < 5; i++ {
+		if _, err := client.collectSwitchMetrics(context.Background(), "1", nil); err != nil {
+			t.Fatalf("collectSwitchMetrics() returned an error: %v", err)
+		}
+	}
+	if elapsed := time.Since(begin); elapsed < 150*time.Millisecond {
+		t.Errorf("performance requests took %s, expected at least 200ms at 20 requests per second", elapsed)
+	}
+
+	if wait := testutil.ToFloat64(rateLimiterWait.WithLabelValues("ratelimit", PerformanceEndpoints)); wait <= 0 {
+		t.Errorf("expected the wait to be counted, got %v", wait)
+	}
+}
@@ -0,0 +1,110 @@
+package spectrumservice
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how the IBM Spectrum GET requests are retried on transient errors
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, 1 disables the retries
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, doubled at each retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between two attempts
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of a new client
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// retryableStatusCodes are the status returned by IBM Spectrum when it is busy
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// backoff returns the wait before the given retry, an exponential backoff with jitter
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	// wait between half and the full backoff, so that the collectors do not retry all together
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// retryAfter reads the Retry-After header, either a number of seconds or an http date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+// retryReason tells whether the failed request can be retried and the reason exposed in the metrics
+func retryReason(ctx context.Context, err error) (string, bool) {
+	if ctx.Err() != nil || !IsRetryable(err) {
+		return "", false
+	}
+	if apiErr, ok := err.(*APIError); ok {
+		return strconv.Itoa(apiErr.StatusCode), true
+	}
+	// the request did not get a response, e.g. connection reset or timeout
+	return "network", true
+}
+
+// sendWithRetries executes the http request, retrying the idempotent ones on transient errors
+func (c *Client) sendWithRetries(ctx context.Context, method, url string, requestBody []byte,
+	paramsMap map[string]string) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		body, err := c.send(ctx, method, url, requestBody, paramsMap)
+		if err == nil || method != http.MethodGet || attempt >= c.Retry.MaxAttempts {
+			return body, err
+		}
+
+		reason, retryable := retryReason(ctx, err)
+		if !retryable {
+			return nil, err
+		}
+
+		wait := c.Retry.backoff(attempt)
+		if apiErr, ok := err.(*APIError); ok {
+			if after := retryAfter(apiErr.RetryAfter); after > wait {
+				wait = after
+			}
+		}
+
+		// no retry when the answer would arrive after the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return nil, err
+		}
+
+		requestRetries.WithLabelValues(c.Name, reason).Inc()
+		c.Sugar.Warnf("Request %s %s failed, retrying in %s. %v", method, url, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package spectrumservice
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/topine/ibm-spectrum-exporter/monitoring"
+)
+
+func TestRetries(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() == authenticate {
+			return
+		}
+		// IBM Spectrum is busy for the first two calls
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(logger.Sugar(), monitoring.MetricsConfig{}, nil, false, "user", "password", server.URL,
+		TLSOptions{})
+	if err != nil {
+		t.Fatalf("NewClient() returned an error: %v", err)
+	}
+	client.Retry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	before := testutil.ToFloat64(requestRetries.WithLabelValues("", "503"))
+
+	if _, err := client.listPools(context.Background()); err != nil {
+		t.Fatalf("listPools() returned an error: %v", err)
+	}
+
+	if retries := testutil.ToFloat64(requestRetries.WithLabelValues("", "503")) - before; retries != 2 {
+		t.Errorf("expected 2 retries, got %v", retries)
+	}
+
+	// the last attempt fails
+	client.Retry.MaxAttempts = 2
+	atomic.StoreInt32(&calls, 0)
+	if _, err := client.listPools(context.Background()); err == nil {
+		t.Error("listPools() expected an error after the retries")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	tests := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{10, 2500 * time.Millisecond, 5 * time.Second},
+	}
+
+	for _, test := range tests {
+		if backoff := policy.backoff(test.retry); backoff < test.min || backoff > test.max {
+			t.Errorf("backoff(%d) = %s, expected between %s and %s", test.retry, backoff, test.min, test.max)
+		}
+	}
+}
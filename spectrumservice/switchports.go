@@ -0,0 +1,78 @@
+package spectrumservice
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// CollectSwitchPortMetrics collects the ports and their performance metrics of the switches matching the filter
+func (c *Client) CollectSwitchPortMetrics(ctx context.Context, filter string) (*CollectedSwitchPortMetrics, error) {
+	begin := time.Now()
+	switches, err := c.listSwitches(ctx)
+	if err != nil {
+		c.Sugar.Error("Error getting switches list.", err)
+		return nil, err
+	}
+
+	var selected []Switch
+	for _, s := range switches {
+		matched, err := regexp.MatchString(filter, strings.ToUpper(s.Name))
+		if err != nil {
+			c.Sugar.Error("Error matching regex.", err)
+			return nil, err
+		}
+		if matched {
+			selected = append(selected, s)
+		}
+	}
+
+	// will get all the stats from the last 20 minutes, as for the switches
+	timeInMillis := time.Now().Add(time.Duration(-20)*time.Minute).UnixNano() / 1000000
+	metricIDs := joinMetricIDs(c.Config.Metrics.SwitchPorts)
+
+	results := make([][]*SwitchPortMetrics, len(selected))
+	err = c.forEach(ctx, len(selected), func(ctx context.Context, i int) error {
+		switchPortMetrics, err := c.collectSwitchPorts(ctx, selected[i], timeInMillis, metricIDs)
+		results[i] = switchPortMetrics
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*SwitchPortMetrics //nolint prealloc
+	for _, switchPortMetrics := range results {
+		response = append(response, switchPortMetrics...)
+	}
+
+	duration := time.Since(begin)
+
+	return &CollectedSwitchPortMetrics{Metrics: response, CollectionDuration: duration.Seconds()}, nil
+}
+
+// collectSwitchPorts collects the ports of a switch and their metrics
+func (c *Client) collectSwitchPorts(ctx context.Context, s Switch, startTime int64,
+	metricIDs string) ([]*SwitchPortMetrics, error) {
+	var ports []SwitchPort
+	if err := c.listResources(ctx, strings.Replace(listSwitchPorts, "{switchID}", s.ID, -1), "Switch "+s.Name,
+		&ports); err != nil {
+		return nil, err
+	}
+
+	metrics, err := c.resourcesMetrics(ctx, strings.Replace(switchPortsPerformance, "{switchID}", s.ID, -1),
+		"Switch "+s.Name, startTime, metricIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*SwitchPortMetrics, 0, len(ports))
+	for _, port := range ports {
+		response = append(response, &SwitchPortMetrics{Switch: s, Port: port, SwitchPortMetrics: metrics[port.ID]})
+	}
+
+	c.Sugar.Infof("Ports retrieved for switch %s : %d", s.Name, len(ports))
+
+	return response, nil
+}
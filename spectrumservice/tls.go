@@ -0,0 +1,100 @@
+package spectrumservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// TLSOptions describes how the IBM Spectrum server certificate is trusted
+type TLSOptions struct {
+	// CAFile is a PEM bundle with the CAs trusted to sign the server certificate, system CAs are used when empty
+	CAFile string
+	// ServerFingerprint is the SHA-256 fingerprint of the server certificate, in hexadecimal with or without colons
+	ServerFingerprint string
+	// CertFile and KeyFile are the client certificate and key for mutual TLS
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version accepted : 1.0, 1.1, 1.2 or 1.3
+	MinVersion string
+	// InsecureSkipVerify accepts any server certificate
+	InsecureSkipVerify bool
+}
+
+// errFingerprintMismatch is returned when the server certificate is not the pinned one
+var errFingerprintMismatch = errors.New("server certificate fingerprint does not match the pinned one")
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// newTLSConfig builds the tls configuration used by the http transport
+func newTLSConfig(sugar *zap.SugaredLogger, options TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if options.MinVersion != "" {
+		version, found := tlsVersions[options.MinVersion]
+		if !found {
+			return nil, fmt.Errorf("unknown TLS version %s", options.MinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	if options.CAFile != "" {
+		pem, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CAFile)
+		}
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if options.ServerFingerprint != "" {
+		fingerprint, err := hex.DecodeString(strings.ReplaceAll(options.ServerFingerprint, ":", ""))
+		if err != nil || len(fingerprint) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 fingerprint %s", options.ServerFingerprint)
+		}
+
+		// a pinned certificate is usually self signed, the chain is only verified when CAs are given
+		config.InsecureSkipVerify = options.CAFile == "" //nolint (the certificate is verified by its fingerprint)
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("no server certificate received")
+			}
+			sum := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(sum[:], fingerprint) {
+				return fmt.Errorf("%w: %x", errFingerprintMismatch, sum)
+			}
+			return nil
+		}
+	}
+
+	if options.InsecureSkipVerify {
+		sugar.Warn("TLS certificate verification of the IBM Spectrum server is disabled, the connection is insecure.")
+		config.InsecureSkipVerify = true //nolint (explicit opt-out of the verification)
+		config.VerifyPeerCertificate = nil
+	}
+
+	return config, nil
+}
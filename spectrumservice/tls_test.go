@@ -0,0 +1,65 @@
+package spectrumservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServerFingerprint(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	sum := sha256.Sum256(server.Certificate().Raw)
+	wrongSum := sha256.Sum256([]byte("another certificate"))
+
+	tests := []struct {
+		fingerprint string
+		valid       bool
+	}{
+		{hex.EncodeToString(sum[:]), true},
+		{hex.EncodeToString(wrongSum[:]), false},
+	}
+
+	for _, test := range tests {
+		config, err := newTLSConfig(logger.Sugar(), TLSOptions{ServerFingerprint: test.fingerprint})
+		if err != nil {
+			t.Fatalf("newTLSConfig() returned an error: %v", err)
+		}
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if (err == nil) != test.valid {
+			t.Errorf("fingerprint %s: expected valid=%v, got error %v", test.fingerprint, test.valid, err)
+		}
+		if err != nil && IsRetryable(err) {
+			t.Errorf("fingerprint %s: the mismatch is not expected to be retried: %v", test.fingerprint, err)
+		}
+	}
+}
+
+func TestUnknownAuthorityNotRetried(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	config, err := newTLSConfig(logger.Sugar(), TLSOptions{})
+	if err != nil {
+		t.Fatalf("newTLSConfig() returned an error: %v", err)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+	resp, err := client.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the self signed certificate to be rejected")
+	}
+	if IsRetryable(err) {
+		t.Errorf("the unknown authority is not expected to be retried: %v", err)
+	}
+}
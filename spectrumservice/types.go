@@ -15,8 +15,19 @@ type CollectedSwitchMetrics struct {
 type StorageMetrics struct {
 	Storage              StorageSystem
 	StorageSystemMetrics []MetricValue
-	VolumeMap            map[string]string
-	VolumeMetrics        []MetricValue
+}
+
+type CollectedVolumeMetrics struct {
+	Metrics            []*VolumeMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// VolumeMetrics are the performance metrics of a volume, the values being matched to the volume by device ID
+type VolumeMetrics struct {
+	Storage       StorageSystem
+	Volume        Volume
+	VolumeMetrics []MetricValue
 }
 
 type SwitchMetrics struct {
@@ -34,6 +45,104 @@ type PoolsMetrics struct {
 	Pool Pool
 }
 
+type CollectedHostMetrics struct {
+	Metrics            []*HostMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// HostMetrics are the performance metrics of a host and the volumes mapped to it
+type HostMetrics struct {
+	Host        Host
+	HostMetrics []MetricValue
+	Volumes     Volumes
+}
+
+type CollectedPortMetrics struct {
+	Metrics            []*PortMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// PortMetrics are the performance metrics of a storage system port
+type PortMetrics struct {
+	Storage     StorageSystem
+	Port        Port
+	PortMetrics []MetricValue
+}
+
+type CollectedNodeMetrics struct {
+	Metrics            []*NodeMetrics
+	IOGroupMetrics     []*IOGroupMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// NodeMetrics are the performance metrics of a storage system node
+type NodeMetrics struct {
+	Storage     StorageSystem
+	Node        Node
+	NodeMetrics []MetricValue
+}
+
+// IOGroupMetrics are the performance metrics of a storage system I/O group
+type IOGroupMetrics struct {
+	Storage        StorageSystem
+	IOGroup        IOGroup
+	IOGroupMetrics []MetricValue
+}
+
+type CollectedManagedDiskMetrics struct {
+	Metrics            []*ManagedDiskMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// ManagedDiskMetrics are the performance metrics of a managed disk or DS8000 rank, Type telling which one
+type ManagedDiskMetrics struct {
+	Storage            StorageSystem
+	Type               string
+	ManagedDisk        ManagedDisk
+	ManagedDiskMetrics []MetricValue
+}
+
+type CollectedDiskMetrics struct {
+	Metrics            []*DiskMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// DiskMetrics is a physical drive of a storage system
+type DiskMetrics struct {
+	Storage StorageSystem
+	Disk    Disk
+}
+
+type CollectedSwitchPortMetrics struct {
+	Metrics            []*SwitchPortMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// SwitchPortMetrics are the performance metrics of a switch port
+type SwitchPortMetrics struct {
+	Switch            Switch
+	Port              SwitchPort
+	SwitchPortMetrics []MetricValue
+}
+
+type CollectedFabricMetrics struct {
+	Metrics            []*FabricMetrics
+	Status             int
+	CollectionDuration float64
+}
+
+// FabricMetrics is a fabric and its member switches
+type FabricMetrics struct {
+	Fabric   Fabric
+	Switches []Switch
+}
+
 // types generated from IBM Spectrum response
 
 //MetricsDetails  for V1
@@ -93,6 +202,7 @@ type StorageSystem struct {
 	PoolCapacity              string `json:"Pool Capacity"`
 	Pools                     string `json:"Pools"`
 	Ports                     string `json:"Ports"`
+	ProbeStatus               string `json:"Probe Status"`
 	RawDiskCapacity           string `json:"Raw Disk Capacity"`
 	ReadCache                 string `json:"Read Cache"`
 	RemoteRelationships       string `json:"Remote Relationships"`
@@ -116,9 +226,39 @@ type StorageSystem struct {
 	ID                        string `json:"id"`
 }
 
-type Volumes []struct {
-	VolumeUniqueID string `json:"Volume Unique ID"`
-	ID             string `json:"id"`
+type Volumes []Volume
+
+type Volume struct {
+	Acknowledged         string `json:"Acknowledged"`
+	AllocatedSpace       string `json:"Allocated Space"`
+	Capacity             string `json:"Capacity"`
+	Compressed           string `json:"Compressed"`
+	CompressionSavings   string `json:"Compression Savings"`
+	CustomTag1           string `json:"Custom Tag 1"`
+	CustomTag2           string `json:"Custom Tag 2"`
+	CustomTag3           string `json:"Custom Tag 3"`
+	Deduplicated         string `json:"Deduplicated"`
+	DeduplicationSavings string `json:"Deduplication Savings"`
+	EasyTier             string `json:"Easy Tier"`
+	Encryption           string `json:"Encryption"`
+	Format               string `json:"Format"`
+	Hosts                string `json:"Hosts"`
+	IOGroup              string `json:"I/O Group"`
+	Mirror               string `json:"Mirror"`
+	Name                 string `json:"Name"`
+	Node                 string `json:"Node"`
+	Pool                 string `json:"Pool"`
+	Shortfall            string `json:"Shortfall"`
+	Status               string `json:"Status"`
+	StorageSystem        string `json:"Storage System"`
+	ThinProvisioned      string `json:"Thin Provisioned"`
+	Tier                 string `json:"Tier"`
+	UnallocatedSpace     string `json:"Unallocated Space"`
+	UsedSpace            string `json:"Used Space"`
+	VirtualAllocation    string `json:"Virtual Allocation"`
+	VolumeUniqueID       string `json:"Volume Unique ID"`
+	WrittenSpace         string `json:"Written Space"`
+	ID                   string `json:"id"`
 }
 
 type Switch struct {
@@ -218,3 +358,122 @@ type Pool struct {
 	ZeroCapacity                string `json:"Zero Capacity"`
 	ID                          string `json:"id"`
 }
+
+type Host struct {
+	Acknowledged   string `json:"Acknowledged"`
+	CustomTag1     string `json:"Custom Tag 1"`
+	CustomTag2     string `json:"Custom Tag 2"`
+	CustomTag3     string `json:"Custom Tag 3"`
+	DataCollection string `json:"Data Collection"`
+	Disks          string `json:"Disks"`
+	IPAddress      string `json:"IP Address"`
+	Location       string `json:"Location"`
+	Name           string `json:"Name"`
+	OSType         string `json:"OS Type"`
+	OSVersion      string `json:"OS Version"`
+	Ports          string `json:"Ports"`
+	Status         string `json:"Status"`
+	StorageSystems string `json:"Storage Systems"`
+	Type           string `json:"Type"`
+	Volumes        string `json:"Volumes"`
+	ID             string `json:"id"`
+}
+
+type Port struct {
+	Acknowledged   string `json:"Acknowledged"`
+	DataCollection string `json:"Data Collection"`
+	IOGroup        string `json:"I/O Group"`
+	Name           string `json:"Name"`
+	Node           string `json:"Node"`
+	Speed          string `json:"Speed"`
+	State          string `json:"State"`
+	Status         string `json:"Status"`
+	StorageSystem  string `json:"Storage System"`
+	Type           string `json:"Type"`
+	WWPN           string `json:"WWPN"`
+	ID             string `json:"id"`
+}
+
+type Node struct {
+	Acknowledged  string `json:"Acknowledged"`
+	IOGroup       string `json:"I/O Group"`
+	IPAddress     string `json:"IP Address"`
+	Name          string `json:"Name"`
+	Status        string `json:"Status"`
+	StorageSystem string `json:"Storage System"`
+	WWNN          string `json:"WWNN"`
+	ID            string `json:"id"`
+}
+
+type IOGroup struct {
+	Acknowledged  string `json:"Acknowledged"`
+	Name          string `json:"Name"`
+	Nodes         string `json:"Nodes"`
+	Status        string `json:"Status"`
+	StorageSystem string `json:"Storage System"`
+	Volumes       string `json:"Volumes"`
+	ID            string `json:"id"`
+}
+
+// ManagedDisk struct for V1, the ranks of the DS8000 have the same fields
+type ManagedDisk struct {
+	Acknowledged         string `json:"Acknowledged"`
+	AvailableSpace       string `json:"Available Space"`
+	BackEndStorageSystem string `json:"Back-end Storage System"`
+	Capacity             string `json:"Capacity"`
+	Class                string `json:"Class"`
+	Mode                 string `json:"Mode"`
+	Name                 string `json:"Name"`
+	Pool                 string `json:"Pool"`
+	RAIDLevel            string `json:"RAID Level"`
+	Status               string `json:"Status"`
+	StorageSystem        string `json:"Storage System"`
+	Tier                 string `json:"Tier"`
+	ID                   string `json:"id"`
+}
+
+type Disk struct {
+	Acknowledged  string `json:"Acknowledged"`
+	Capacity      string `json:"Capacity"`
+	Class         string `json:"Class"`
+	Enclosure     string `json:"Enclosure"`
+	Firmware      string `json:"Firmware"`
+	Model         string `json:"Model"`
+	Name          string `json:"Name"`
+	SerialNumber  string `json:"Serial Number"`
+	Slot          string `json:"Slot"`
+	Speed         string `json:"Speed"`
+	Status        string `json:"Status"`
+	StorageSystem string `json:"Storage System"`
+	Tier          string `json:"Tier"`
+	Use           string `json:"Use"`
+	Vendor        string `json:"Vendor"`
+	ID            string `json:"id"`
+}
+
+type SwitchPort struct {
+	Acknowledged   string `json:"Acknowledged"`
+	ConnectedPorts string `json:"Connected Ports"`
+	Name           string `json:"Name"`
+	PortIndex      string `json:"Port Index"`
+	Speed          string `json:"Speed"`
+	State          string `json:"State"`
+	Status         string `json:"Status"`
+	Switch         string `json:"Switch"`
+	Type           string `json:"Type"`
+	WWPN           string `json:"WWPN"`
+	ID             string `json:"id"`
+}
+
+type Fabric struct {
+	Acknowledged    string `json:"Acknowledged"`
+	Name            string `json:"Name"`
+	Ports           string `json:"Ports"`
+	PrincipalSwitch string `json:"Principal Switch"`
+	Status          string `json:"Status"`
+	Switches        string `json:"Switches"`
+	Vendor          string `json:"Vendor"`
+	VirtualFabric   string `json:"Virtual Fabric"`
+	WWN             string `json:"WWN"`
+	ID              string `json:"id"`
+}